@@ -7,7 +7,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -587,6 +590,568 @@ func TestStudioMCPServerIntegration(t *testing.T) {
 		})
 	})
 
+	t.Run("SeveralCommands", func(t *testing.T) {
+		commandArgs := []string{"echo", "{{text}}", "---", "date", "[+format]", "---", "printf", `\---`}
+
+		t.Run("lists a tool for each command", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "18",
+				Method:  "tools/list",
+			}
+
+			response := sendMCPRequest(t, commandArgs, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+
+			var names []string
+			for _, item := range tools {
+				tool, ok := item.(map[string]interface{})
+				require.True(t, ok)
+				names = append(names, tool["name"].(string))
+			}
+			assert.ElementsMatch(t, []string{"echo", "date", "printf"}, names)
+		})
+
+		t.Run("keeps escaped separators as arguments", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "19",
+				Method:  "tools/call",
+				Params: map[string]interface{}{
+					"name":      "printf",
+					"arguments": map[string]interface{}{},
+				},
+			}
+
+			response := sendMCPRequest(t, commandArgs, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			content, ok := result["content"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, content, 1)
+
+			textContent, ok := content[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "---", textContent["text"])
+		})
+	})
+
+	t.Run("ToolNames", func(t *testing.T) {
+		t.Run("names tools after their commands unless overridden", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "17",
+				Method:  "tools/list",
+			}
+
+			commandArgs := []string{
+				"--title", "Echo", "/bin/echo", "{{text}}",
+				"---", "sh", "./scripts/report.sh",
+				"---", "sh", "-e", "./scripts/backup.sh",
+				"---", "--name", "greet", "--description", "Say hello", "echo", "hello",
+			}
+			response := sendMCPRequest(t, commandArgs, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+
+			listed := map[string][]interface{}{}
+			for _, item := range tools {
+				tool, ok := item.(map[string]interface{})
+				require.True(t, ok)
+				listed[tool["name"].(string)] = []interface{}{tool["title"], tool["description"]}
+			}
+			assert.Equal(t, map[string][]interface{}{
+				"echo":      {"Echo", "Run the shell command `/bin/echo {{text}}`"},
+				"sh_report": {nil, "Run the shell command `sh ./scripts/report.sh`"},
+				"sh_backup": {nil, "Run the shell command `sh -e ./scripts/backup.sh`"},
+				"greet":     {nil, "Say hello"},
+			}, listed)
+		})
+	})
+
+	t.Run("ToolAnnotations", func(t *testing.T) {
+		t.Run("lists declared and inferred annotations", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "16",
+				Method:  "tools/list",
+			}
+
+			commandArgs := []string{
+				"--infer-annotations", "rm", "{{file}}",
+				"---", "--infer-annotations", "--idempotent", "git", "status",
+				"---", "--read-only", "say", "{{text}}",
+				"---", "touch", "{{file}}",
+			}
+			response := sendMCPRequest(t, commandArgs, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+
+			annotations := map[string]interface{}{}
+			for _, item := range tools {
+				tool, ok := item.(map[string]interface{})
+				require.True(t, ok)
+				annotations[tool["name"].(string)] = tool["annotations"]
+			}
+			assert.Equal(t, map[string]interface{}{
+				"rm":    map[string]interface{}{"destructiveHint": true, "openWorldHint": false},
+				"git":   map[string]interface{}{"readOnlyHint": true, "destructiveHint": false, "idempotentHint": true, "openWorldHint": false},
+				"say":   map[string]interface{}{"readOnlyHint": true},
+				"touch": nil,
+			}, annotations)
+		})
+	})
+
+	t.Run("ConfigFile", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "tools.yaml")
+		err := os.WriteFile(configPath, []byte(`
+tools:
+  - name: shout
+    description: Echo text in capitals
+    command: [sh, -c, 'echo "$0" | tr a-z A-Z', "{{text}}"]
+  - command: [pwd]
+    dir: /
+`), 0644)
+		require.NoError(t, err)
+
+		t.Run("lists every tool in the config", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "20",
+				Method:  "tools/list",
+			}
+
+			response := sendMCPRequest(t, []string{"--config", configPath, "echo", "{{text}}"}, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, tools, 3)
+
+			descriptions := map[string]interface{}{}
+			for _, item := range tools {
+				tool, ok := item.(map[string]interface{})
+				require.True(t, ok)
+				descriptions[tool["name"].(string)] = tool["description"]
+			}
+			assert.Equal(t, map[string]interface{}{
+				"shout": "Echo text in capitals",
+				"pwd":   "Run the shell command `pwd`",
+				"echo":  "Run the shell command `echo {{text}}`",
+			}, descriptions)
+		})
+
+		t.Run("runs tools with their options", func(t *testing.T) {
+			for _, call := range []struct {
+				name     string
+				args     map[string]interface{}
+				expected string
+			}{
+				{name: "shout", args: map[string]interface{}{"text": "hello"}, expected: "HELLO"},
+				{name: "pwd", args: map[string]interface{}{}, expected: "/"},
+			} {
+				request := MCPRequest{
+					JSONRPC: "2.0",
+					ID:      "21",
+					Method:  "tools/call",
+					Params: map[string]interface{}{
+						"name":      call.name,
+						"arguments": call.args,
+					},
+				}
+
+				response := sendMCPRequest(t, []string{"--config", configPath}, request, timeout)
+
+				result, ok := response.Result.(map[string]interface{})
+				require.True(t, ok)
+
+				content, ok := result["content"].([]interface{})
+				require.True(t, ok)
+				require.Len(t, content, 1)
+
+				textContent, ok := content[0].(map[string]interface{})
+				require.True(t, ok)
+				assert.Equal(t, call.expected, textContent["text"])
+			}
+		})
+	})
+
+	t.Run("Subcommands", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "tools.yaml")
+		err := os.WriteFile(configPath, []byte(`
+tools:
+  - command: [sh, -c, 'echo "$@"', sh, "[--verbose]"]
+    subcommands:
+      - command: [hello, "{{name}}"]
+      - command: [goodbye]
+`), 0644)
+		require.NoError(t, err)
+
+		request := MCPRequest{
+			JSONRPC: "2.0",
+			ID:      "28",
+			Method:  "tools/list",
+		}
+
+		response := sendMCPRequest(t, []string{"--config", configPath}, request, timeout)
+
+		result, ok := response.Result.(map[string]interface{})
+		require.True(t, ok)
+
+		tools, ok := result["tools"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, tools, 2)
+
+		properties := map[string][]string{}
+		for _, item := range tools {
+			tool, ok := item.(map[string]interface{})
+			require.True(t, ok)
+			schema, ok := tool["inputSchema"].(map[string]interface{})
+			require.True(t, ok)
+			props, _ := schema["properties"].(map[string]interface{})
+			var names []string
+			for name := range props {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			properties[tool["name"].(string)] = names
+		}
+		assert.Equal(t, map[string][]string{
+			"sh_hello":   {"name", "verbose"},
+			"sh_goodbye": {"verbose"},
+		}, properties)
+	})
+
+	t.Run("ScriptsDir", func(t *testing.T) {
+		scriptsDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(scriptsDir, "greet.sh"), []byte(`#!/bin/sh
+# studio: {{name # who to greet}} [--loud # shout the greeting]
+# description: Greet someone by name
+if [ "$2" = "--loud" ]; then echo "HELLO $1"; else echo "hello $1"; fi
+`), 0755)
+		require.NoError(t, err)
+
+		t.Run("lists scripts with their header", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "22",
+				Method:  "tools/list",
+			}
+
+			response := sendMCPRequest(t, []string{"--scripts-dir", scriptsDir}, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, tools, 1)
+
+			tool, ok := tools[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "greet", tool["name"])
+			assert.Equal(t, "Greet someone by name", tool["description"])
+
+			schema, ok := tool["inputSchema"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, []interface{}{"name"}, schema["required"])
+		})
+
+		t.Run("runs the script", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "23",
+				Method:  "tools/call",
+				Params: map[string]interface{}{
+					"name":      "greet",
+					"arguments": map[string]interface{}{"name": "world", "loud": true},
+				},
+			}
+
+			response := sendMCPRequest(t, []string{"--scripts-dir", scriptsDir}, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			content, ok := result["content"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, content, 1)
+
+			textContent, ok := content[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "HELLO world", textContent["text"])
+		})
+	})
+
+	t.Run("Makefile", func(t *testing.T) {
+		makefile := filepath.Join(t.TempDir(), "Makefile")
+		err := os.WriteFile(makefile, []byte(`.PHONY: greet internal
+GREETING ?= hello
+
+greet: ## Greet someone [NAME # who to greet] [GREETING = hi]
+	@echo "$(GREETING) $(NAME)"
+
+internal:
+	@echo "not served"
+`), 0644)
+		require.NoError(t, err)
+
+		t.Run("lists documented targets", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "24",
+				Method:  "tools/list",
+			}
+
+			response := sendMCPRequest(t, []string{"--make", makefile}, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, tools, 1)
+
+			tool, ok := tools[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "make_greet", tool["name"])
+			assert.Equal(t, "Greet someone", tool["description"])
+
+			schema, ok := tool["inputSchema"].(map[string]interface{})
+			require.True(t, ok)
+			properties, ok := schema["properties"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Contains(t, properties, "NAME")
+			assert.Contains(t, properties, "GREETING")
+		})
+
+		t.Run("runs the target with variable overrides", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "25",
+				Method:  "tools/call",
+				Params: map[string]interface{}{
+					"name":      "make_greet",
+					"arguments": map[string]interface{}{"NAME": "world"},
+				},
+			}
+
+			response := sendMCPRequest(t, []string{"--make", makefile}, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			content, ok := result["content"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, content, 1)
+
+			textContent, ok := content[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "hi world", textContent["text"])
+		})
+	})
+
+	t.Run("NPMScripts", func(t *testing.T) {
+		packageJSON := filepath.Join(t.TempDir(), "package.json")
+		err := os.WriteFile(packageJSON, []byte(`{
+  "name": "npm-scripts-test",
+  "scripts": {
+    "greet": "echo hello",
+    "postinstall": "echo installed"
+  },
+  "studio": {
+    "greet": "Say hello to someone"
+  }
+}`), 0644)
+		require.NoError(t, err)
+
+		t.Run("lists scripts with their descriptions", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "26",
+				Method:  "tools/list",
+			}
+
+			response := sendMCPRequest(t, []string{"--npm-scripts", packageJSON}, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, tools, 1)
+
+			tool, ok := tools[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "npm_greet", tool["name"])
+			assert.Equal(t, "Say hello to someone", tool["description"])
+
+			schema, ok := tool["inputSchema"].(map[string]interface{})
+			require.True(t, ok)
+			properties, ok := schema["properties"].(map[string]interface{})
+			require.True(t, ok)
+			args, ok := properties["args"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "array", args["type"])
+		})
+
+		t.Run("runs the script with extra args", func(t *testing.T) {
+			if _, err := exec.LookPath("npm"); err != nil {
+				t.Skip("npm is not installed")
+			}
+
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "27",
+				Method:  "tools/call",
+				Params: map[string]interface{}{
+					"name":      "npm_greet",
+					"arguments": map[string]interface{}{"args": []interface{}{"world"}},
+				},
+			}
+
+			response := sendMCPRequest(t, []string{"--npm-scripts", packageJSON}, request, 10*time.Second)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			content, ok := result["content"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, content, 1)
+
+			textContent, ok := content[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Contains(t, textContent["text"], "hello world")
+		})
+	})
+
+	t.Run("OutputLimits", func(t *testing.T) {
+		args := []string{"--max-output-lines", "4", "seq", "{{count}}"}
+
+		t.Run("lists the read_output tool", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "30",
+				Method:  "tools/list",
+			}
+
+			response := sendMCPRequest(t, args, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+
+			tools, ok := result["tools"].([]interface{})
+			require.True(t, ok)
+
+			var names []string
+			for _, item := range tools {
+				tool, ok := item.(map[string]interface{})
+				require.True(t, ok)
+				names = append(names, tool["name"].(string))
+			}
+			sort.Strings(names)
+			assert.Equal(t, []string{"read_output", "seq"}, names)
+		})
+
+		t.Run("truncates long output", func(t *testing.T) {
+			request := MCPRequest{
+				JSONRPC: "2.0",
+				ID:      "31",
+				Method:  "tools/call",
+				Params: map[string]interface{}{
+					"name":      "seq",
+					"arguments": map[string]interface{}{"count": "100"},
+				},
+			}
+
+			response := sendMCPRequest(t, args, request, timeout)
+
+			result, ok := response.Result.(map[string]interface{})
+			require.True(t, ok)
+			content, ok := result["content"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, content, 1)
+
+			textContent, ok := content[0].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, "1\n2\n… 96 lines omitted …\n99\n100\n\n"+
+				`[Output truncated from 100 lines and 291 bytes. Call read_output with id "out-1" to read all of it.]`, textContent["text"])
+		})
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		t.Run("stops running commands on SIGTERM", func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				t.Skip("SIGTERM isn't delivered on Windows")
+			}
+
+			dir := t.TempDir()
+			started, stopped := filepath.Join(dir, "started"), filepath.Join(dir, "stopped")
+			script := "trap 'touch " + stopped + "; exit 1' TERM; touch " + started + "; sleep 30 & wait"
+
+			projectRoot, err := filepath.Abs("..")
+			require.NoError(t, err)
+			binaryPath := filepath.Join(projectRoot, "bin", "studio-mcp")
+			buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+			buildCmd.Dir = projectRoot
+			require.NoError(t, buildCmd.Run(), "Failed to build project")
+
+			cmd := exec.Command(binaryPath, "sh", "-c", script)
+			stdin, err := cmd.StdinPipe()
+			require.NoError(t, err)
+			require.NoError(t, cmd.Start())
+			defer cmd.Process.Kill()
+
+			for _, request := range []MCPRequest{
+				{JSONRPC: "2.0", ID: "init", Method: "initialize", Params: InitializeParams{ProtocolVersion: "2024-11-05", Capabilities: map[string]interface{}{}, ClientInfo: map[string]interface{}{"name": "test-client", "version": "1.0.0"}}},
+				{JSONRPC: "2.0", ID: "29", Method: "tools/call", Params: map[string]interface{}{"name": "sh", "arguments": map[string]interface{}{}}},
+			} {
+				requestJSON, err := json.Marshal(request)
+				require.NoError(t, err)
+				_, err = stdin.Write(append(requestJSON, '\n'))
+				require.NoError(t, err)
+			}
+
+			require.Eventually(t, func() bool {
+				_, err := os.Stat(started)
+				return err == nil
+			}, timeout, 10*time.Millisecond, "the command didn't start")
+
+			require.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+
+			exited := make(chan struct{})
+			go func() {
+				cmd.Wait()
+				close(exited)
+			}()
+			select {
+			case <-exited:
+			case <-time.After(timeout):
+				t.Fatal("the server didn't exit after SIGTERM")
+			}
+
+			_, err = os.Stat(stopped)
+			assert.NoError(t, err, "the command didn't get SIGTERM")
+		})
+	})
+
 	t.Run("ErrorHandling", func(t *testing.T) {
 		t.Run("handles command errors gracefully", func(t *testing.T) {
 			request := MCPRequest{
@@ -24,8 +24,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"studio-mcp/internal/config"
 	"studio-mcp/internal/studio"
+	"studio-mcp/internal/tool"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -37,8 +42,122 @@ var (
 	Date    string
 )
 
+// options holds the studio-mcp flags that come before the command
+type options struct {
+	Debug      bool
+	Version    bool
+	Config     string        // Path to a YAML or JSON file declaring tools
+	ScriptsDir string        // Directory of executable scripts to serve as tools
+	Makefile   string        // Makefile whose documented targets to serve as tools
+	NPMScripts string        // package.json whose scripts to serve as tools
+	Timeout    time.Duration // Timeout for every tool that doesn't set its own
+	Tool       tool.Options  // Name, title and description for the command's tool
+	Command    []string      // The blueprint, starting at the first non-flag argument
+
+	MaxOutputLines int // Most lines of output in a tool result; zero means no limit
+	MaxOutputBytes int // Most bytes of output in a tool result; zero means 512 KB
+}
+
+// hasSources reports whether any flag declares tools without a command
+func (opts options) hasSources() bool {
+	return opts.Config != "" || opts.ScriptsDir != "" || opts.Makefile != "" || opts.NPMScripts != ""
+}
+
+// toolFlags set the options for the tool made from the command that follows them
+var toolFlags = map[string]func(opts *tool.Options, value string) error{
+	"--name":        func(opts *tool.Options, value string) error { opts.Name = value; return nil },
+	"--title":       func(opts *tool.Options, value string) error { opts.Title = value; return nil },
+	"--description": func(opts *tool.Options, value string) error { opts.Description = value; return nil },
+	"--timeout": func(opts *tool.Options, value string) (err error) {
+		opts.Timeout, err = config.ParseDuration(value)
+		return err
+	},
+}
+
+// toolSwitches are boolean toolFlags that don't take a separate value: "--read-only"
+// or "--read-only=false"
+var toolSwitches = map[string]func(opts *tool.Options, value bool){
+	"--read-only":         func(opts *tool.Options, value bool) { opts.Annotations.ReadOnly = &value },
+	"--destructive":       func(opts *tool.Options, value bool) { opts.Annotations.Destructive = &value },
+	"--idempotent":        func(opts *tool.Options, value bool) { opts.Annotations.Idempotent = &value },
+	"--open-world":        func(opts *tool.Options, value bool) { opts.Annotations.OpenWorld = &value },
+	"--infer-annotations": func(opts *tool.Options, value bool) { opts.InferAnnotations = value },
+}
+
+// parseToolFlag parses args[*i] if it is one of the toolFlags or toolSwitches, given as
+// "--flag value" or "--flag=value", and advances i past a separate value
+func parseToolFlag(args []string, i *int, opts *tool.Options) (bool, error) {
+	flag, value, hasValue := strings.Cut(args[*i], "=")
+	if set, ok := toolSwitches[flag]; ok {
+		enabled := true
+		if hasValue {
+			var err error
+			if enabled, err = strconv.ParseBool(value); err != nil {
+				return true, fmt.Errorf("%s must be true or false, got %q", flag, value)
+			}
+		}
+		set(opts, enabled)
+		return true, nil
+	}
+
+	set, ok := toolFlags[flag]
+	if !ok {
+		return false, nil
+	}
+
+	if !hasValue {
+		if *i+1 >= len(args) {
+			return true, fmt.Errorf("%s requires a value", flag)
+		}
+		*i++
+		value = args[*i]
+	}
+
+	if err := set(opts, value); err != nil {
+		return true, fmt.Errorf("%s: %w", flag, err)
+	}
+	return true, nil
+}
+
+// parseLimitFlag parses a limit flag at args[*i], given as "--flag n" or "--flag=n",
+// and advances i past a separate value
+func parseLimitFlag(args []string, i *int) (int, error) {
+	flag, value, hasValue := strings.Cut(args[*i], "=")
+	if !hasValue {
+		if *i+1 >= len(args) {
+			return 0, fmt.Errorf("%s requires a value", flag)
+		}
+		*i++
+		value = args[*i]
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("%s must be a whole number more than zero, got %q", flag, value)
+	}
+	return limit, nil
+}
+
+// defaultMakefile is the Makefile served by --make without a path
+const defaultMakefile = "Makefile"
+
+// defaultPackageJSON is the package.json served by --npm-scripts without a path
+const defaultPackageJSON = "package.json"
+
+// isMakefileName reports whether a path is named like a Makefile: Makefile, makefile,
+// GNUmakefile or anything ending in .mk or .make
+func isMakefileName(path string) bool {
+	switch name := filepath.Base(path); name {
+	case "Makefile", "makefile", "GNUmakefile":
+		return true
+	default:
+		ext := filepath.Ext(name)
+		return ext == ".mk" || ext == ".make"
+	}
+}
+
 // parseArgs parses arguments manually, stopping flag parsing at first non-flag
-func parseArgs(args []string) (debugFlag bool, versionFlag bool, commandArgs []string, err error) {
+func parseArgs(args []string) (opts options, err error) {
 	i := 0
 
 	// Parse studio-mcp flags until we hit a non-flag
@@ -50,36 +169,179 @@ func parseArgs(args []string) (debugFlag bool, versionFlag bool, commandArgs []s
 			break
 		}
 
-		switch arg {
-		case "--debug":
-			debugFlag = true
-		case "--version":
-			versionFlag = true
-		case "-h", "--help":
+		switch {
+		case arg == "--debug":
+			opts.Debug = true
+		case arg == "--version":
+			opts.Version = true
+		case arg == "--config":
+			if i+1 >= len(args) {
+				return options{}, fmt.Errorf("--config requires a file path")
+			}
+			i++
+			opts.Config = args[i]
+		case strings.HasPrefix(arg, "--config="):
+			opts.Config = strings.TrimPrefix(arg, "--config=")
+		case arg == "--scripts-dir":
+			if i+1 >= len(args) {
+				return options{}, fmt.Errorf("--scripts-dir requires a directory path")
+			}
+			i++
+			opts.ScriptsDir = args[i]
+		case strings.HasPrefix(arg, "--scripts-dir="):
+			opts.ScriptsDir = strings.TrimPrefix(arg, "--scripts-dir=")
+		case arg == "--make":
+			// The path is optional, so only take the next argument if it names a Makefile
+			opts.Makefile = defaultMakefile
+			if i+1 < len(args) && isMakefileName(args[i+1]) {
+				i++
+				opts.Makefile = args[i]
+			}
+		case strings.HasPrefix(arg, "--make="):
+			opts.Makefile = strings.TrimPrefix(arg, "--make=")
+		case arg == "--npm-scripts":
+			// Like --make, only take the next argument if it names a package.json
+			opts.NPMScripts = defaultPackageJSON
+			if i+1 < len(args) && filepath.Base(args[i+1]) == defaultPackageJSON {
+				i++
+				opts.NPMScripts = args[i]
+			}
+		case strings.HasPrefix(arg, "--npm-scripts="):
+			opts.NPMScripts = strings.TrimPrefix(arg, "--npm-scripts=")
+		case arg == "--timeout" || strings.HasPrefix(arg, "--timeout="):
+			// Before the first command, --timeout is the default for every tool
+			var defaults tool.Options
+			if _, err := parseToolFlag(args, &i, &defaults); err != nil {
+				return options{}, err
+			}
+			opts.Timeout = defaults.Timeout
+		case arg == "--max-output-lines" || strings.HasPrefix(arg, "--max-output-lines="):
+			if opts.MaxOutputLines, err = parseLimitFlag(args, &i); err != nil {
+				return options{}, err
+			}
+		case arg == "--max-output-bytes" || strings.HasPrefix(arg, "--max-output-bytes="):
+			if opts.MaxOutputBytes, err = parseLimitFlag(args, &i); err != nil {
+				return options{}, err
+			}
+		case arg == "-h" || arg == "--help":
 			// Let cobra handle help
-			return false, false, nil, fmt.Errorf("help requested")
+			return options{}, fmt.Errorf("help requested")
 		default:
-			return false, false, nil, fmt.Errorf("unknown flag: %s", arg)
+			if ok, err := parseToolFlag(args, &i, &opts.Tool); err != nil {
+				return options{}, err
+			} else if !ok {
+				return options{}, fmt.Errorf("unknown flag: %s", arg)
+			}
 		}
 
 		i++
 	}
 
 	// Everything from i onwards goes to blueprint parsing
-	commandArgs = args[i:]
+	opts.Command = args[i:]
+
+	return opts, nil
+}
+
+// commandSeparator separates blueprints for several tools on the command line
+const commandSeparator = "---"
+
+// splitCommands splits command arguments into one blueprint per tool on "---".
+// A backslash keeps a literal "---" in a blueprint: "\---" becomes "---".
+func splitCommands(args []string) ([][]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	commands := [][]string{{}}
+	for _, arg := range args {
+		if arg == commandSeparator {
+			commands = append(commands, []string{})
+			continue
+		}
+		if strings.HasPrefix(arg, `\`) && strings.TrimLeft(arg, `\`) == commandSeparator {
+			arg = arg[1:]
+		}
+		commands[len(commands)-1] = append(commands[len(commands)-1], arg)
+	}
 
-	return debugFlag, versionFlag, commandArgs, nil
+	for i, command := range commands {
+		if len(command) == 0 {
+			return nil, fmt.Errorf("tool %d has no command: put a command between each %q", i+1, commandSeparator)
+		}
+	}
+	return commands, nil
+}
+
+// parseCommands splits command arguments into commands for each tool. Commands after a
+// "---" may start with their own tool flags; the first command uses the ones in opts.
+func parseCommands(opts options) ([]studio.Command, error) {
+	split, err := splitCommands(opts.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]studio.Command, len(split))
+	for n, args := range split {
+		if n == 0 {
+			commands[n] = studio.Command{Args: args, Options: opts.Tool}
+			continue
+		}
+
+		i := 0
+		for ; i < len(args) && strings.HasPrefix(args[i], "-"); i++ {
+			if ok, err := parseToolFlag(args, &i, &commands[n].Options); err != nil {
+				return nil, err
+			} else if !ok {
+				return nil, fmt.Errorf("unknown flag for tool %d: %s", n+1, args[i])
+			}
+		}
+		if i == len(args) {
+			return nil, fmt.Errorf("tool %d has no command: put a command between each %q", n+1, commandSeparator)
+		}
+		commands[n].Args = args[i:]
+	}
+	return commands, nil
 }
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "studio-mcp [--debug] <command> --example \"{{req # required arg}}\" \"[args... # array of args]\"",
+	Use:   "studio-mcp [--debug] [--config tools.yaml] [--scripts-dir dir] [--make [Makefile]] [--npm-scripts [package.json]] <command> --example \"{{req # required arg}}\" \"[args... # array of args]\"",
 	Short: "A tool for running a single command MCP server",
 	Long: `studio-mcp is a tool for running a single command MCP server.
 
   -h, --help - Show this help message and exit.
   --version - Show version information and exit.
   --debug - Print debug logs to stderr to diagnose MCP server issues.
+  --config <file> - Serve every tool declared in a YAML or JSON file. The command is optional.
+              The tools are reloaded when the file changes.
+  --scripts-dir <dir> - Serve every executable script in a directory, described by header
+              comments like "# studio: {{env # target env}} [--dry-run]" and
+              "# description: ...". Scripts are reloaded when they change.
+  --make [Makefile] - Serve the targets of a Makefile that have a "## description" comment.
+              Uppercase tags in the comment like [VERSION # version to build] become
+              VERSION=value overrides. Use --make=path for files not named like a Makefile.
+  --npm-scripts [package.json] - Serve each script in a package.json as npm run <name> -- [args...].
+              Descriptions come from a "studio" object of script names and descriptions.
+  --name <name> - The tool name. Defaults to the command name, e.g. deploy for ./scripts/deploy.sh.
+  --title <title> - A human readable tool name for clients to show.
+  --description <text> - The tool description. Defaults to "Run the shell command ...".
+  --read-only, --destructive, --idempotent, --open-world - Hints for clients about what
+              the tool does. Add =false to declare the opposite, e.g. --destructive=false.
+  --infer-annotations - Guess undeclared hints for well known commands, e.g. rm or git push.
+  --timeout <duration> - Stop commands that run longer than this, e.g. 30s or 2m, along with
+              everything they started. Before the first command it applies to every tool;
+              after a --- it applies to that command's tool. On Windows only the command
+              itself is stopped.
+  --max-output-lines <n>, --max-output-bytes <n> - Cut tool results down to about this many
+              lines or bytes, keeping the first and last lines. The full output can be read
+              a page at a time with the read_output tool. Without them, results are cut at
+              512 KB.
+
+separate commands with --- to serve more than one tool (use \--- for a literal ---).
+tool options like --name can follow each ---:
+
+  studio-mcp echo "{{text}}" --- --name today date "[+format]" --- ls "[path]"
 
 the command starts at the first non-flag argument:
 
@@ -99,7 +361,7 @@ Example:
 	DisableFlagParsing: true, // Disable cobra's flag parsing so we can do custom parsing
 	Args: func(cmd *cobra.Command, args []string) error {
 		// Custom argument parsing
-		_, versionFlag, commandArgs, err := parseArgs(args)
+		opts, err := parseArgs(args)
 		if err != nil {
 			if err.Error() == "help requested" {
 				return nil // Let cobra handle help
@@ -108,18 +370,19 @@ Example:
 		}
 
 		// If version flag is set, don't validate command args
-		if versionFlag {
+		if opts.Version {
 			return nil
 		}
 
-		if len(commandArgs) == 0 {
+		// A config file, scripts directory, Makefile or package.json declares its own commands
+		if len(opts.Command) == 0 && !opts.hasSources() {
 			return fmt.Errorf("usage: studio-mcp <command> --example \"{{req # required arg}}\" \"[args... # array of args]\"")
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Parse arguments manually
-		debugFlag, versionFlag, commandArgs, err := parseArgs(args)
+		opts, err := parseArgs(args)
 		if err != nil {
 			if err.Error() == "help requested" {
 				return cmd.Help()
@@ -128,15 +391,31 @@ Example:
 		}
 
 		// Handle version flag
-		if versionFlag {
+		if opts.Version {
 			cmd.Printf("studio-mcp %s\n", Version)
 			cmd.Printf("commit: %s\n", Commit)
 			cmd.Printf("built: %s\n", Date)
 			return nil
 		}
 
-		// Create a new Studio instance with the command args
-		s, err := studio.New(commandArgs, debugFlag, Version)
+		commands, err := parseCommands(opts)
+		if err != nil {
+			return err
+		}
+
+		// Create a new Studio instance with the tool sources and command args
+		sources := studio.Sources{
+			ConfigPath: opts.Config,
+			ScriptsDir: opts.ScriptsDir,
+			Makefile:   opts.Makefile,
+			NPMScripts: opts.NPMScripts,
+			Commands:   commands,
+			Timeout:    opts.Timeout,
+
+			MaxOutputLines: opts.MaxOutputLines,
+			MaxOutputBytes: opts.MaxOutputBytes,
+		}
+		s, err := studio.NewFromSources(sources, opts.Debug, Version)
 		if err != nil {
 			return err
 		}
@@ -1,17 +1,26 @@
 package cmd
 
 import (
+	"studio-mcp/internal/studio"
+	"studio-mcp/internal/tool"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestParseArgs(t *testing.T) {
+	yes, no := true, false
 	tests := []struct {
 		name            string
 		args            []string
 		expectedDebug   bool
 		expectedVersion bool
+		expectedConfig  string
+		expectedScripts string
+		expectedMake    string
+		expectedNPM     string
+		expectedTool    tool.Options
 		expectedCommand []string
 		expectedError   string
 	}{
@@ -57,6 +66,118 @@ func TestParseArgs(t *testing.T) {
 			expectedVersion: false,
 			expectedCommand: []string{"curl", "-X", "POST", "-H", "Content-Type: application/json", "{{url}}"},
 		},
+		{
+			name:            "config flag without a command",
+			args:            []string{"--config", "tools.yaml"},
+			expectedConfig:  "tools.yaml",
+			expectedCommand: []string{},
+		},
+		{
+			name:            "config flag with equals and a command",
+			args:            []string{"--debug", "--config=tools.json", "echo", "{{text}}"},
+			expectedDebug:   true,
+			expectedConfig:  "tools.json",
+			expectedCommand: []string{"echo", "{{text}}"},
+		},
+		{
+			name:            "scripts dir flag",
+			args:            []string{"--scripts-dir", "./scripts", "--config=tools.yaml"},
+			expectedConfig:  "tools.yaml",
+			expectedScripts: "./scripts",
+			expectedCommand: []string{},
+		},
+		{
+			name:            "scripts dir flag with equals and a command",
+			args:            []string{"--scripts-dir=bin", "date"},
+			expectedScripts: "bin",
+			expectedCommand: []string{"date"},
+		},
+		{
+			name:            "make flag without a path",
+			args:            []string{"--make", "echo", "{{text}}"},
+			expectedMake:    "Makefile",
+			expectedCommand: []string{"echo", "{{text}}"},
+		},
+		{
+			name:            "make flag with a Makefile path",
+			args:            []string{"--make", "../project/GNUmakefile", "--debug"},
+			expectedDebug:   true,
+			expectedMake:    "../project/GNUmakefile",
+			expectedCommand: []string{},
+		},
+		{
+			name:            "make flag with an included makefile",
+			args:            []string{"--make", "build.mk"},
+			expectedMake:    "build.mk",
+			expectedCommand: []string{},
+		},
+		{
+			name:            "make flag with equals",
+			args:            []string{"--make=tasks"},
+			expectedMake:    "tasks",
+			expectedCommand: []string{},
+		},
+		{
+			name:            "npm scripts flag without a path",
+			args:            []string{"--npm-scripts", "node", "{{file}}"},
+			expectedNPM:     "package.json",
+			expectedCommand: []string{"node", "{{file}}"},
+		},
+		{
+			name:            "npm scripts flag with a path",
+			args:            []string{"--npm-scripts", "web/package.json", "--make"},
+			expectedNPM:     "web/package.json",
+			expectedMake:    "Makefile",
+			expectedCommand: []string{},
+		},
+		{
+			name:            "npm scripts flag with equals",
+			args:            []string{"--npm-scripts=web/app.json"},
+			expectedNPM:     "web/app.json",
+			expectedCommand: []string{},
+		},
+		{
+			name:            "tool flags",
+			args:            []string{"--name", "speak", "--title=Speak out loud", "--description", "Say -v anything", "say", "{{text}}"},
+			expectedTool:    tool.Options{Name: "speak", Title: "Speak out loud", Description: "Say -v anything"},
+			expectedCommand: []string{"say", "{{text}}"},
+		},
+		{
+			name:            "annotation flags",
+			args:            []string{"--read-only", "--destructive=false", "--open-world=0", "--infer-annotations", "cat", "[file]"},
+			expectedTool:    tool.Options{Annotations: tool.Annotations{ReadOnly: &yes, Destructive: &no, OpenWorld: &no}, InferAnnotations: true},
+			expectedCommand: []string{"cat", "[file]"},
+		},
+		{
+			name:          "annotation flag with an invalid value",
+			args:          []string{"--idempotent=maybe", "touch", "{{file}}"},
+			expectedError: `--idempotent must be true or false, got "maybe"`,
+		},
+		{
+			name:          "tool flag without a value",
+			args:          []string{"--title"},
+			expectedError: "--title requires a value",
+		},
+		{
+			name:          "config flag without a path",
+			args:          []string{"--config"},
+			expectedError: "--config requires a file path",
+		},
+		{
+			name:          "scripts dir flag without a path",
+			args:          []string{"--scripts-dir"},
+			expectedError: "--scripts-dir requires a directory path",
+		},
+		{
+			name:          "output limit flag without a value",
+			args:          []string{"--max-output-lines"},
+			expectedError: "--max-output-lines requires a value",
+		},
+		{
+			name:          "output limit flag with an invalid value",
+			args:          []string{"--max-output-bytes=0", "find", "/"},
+			expectedError: `--max-output-bytes must be a whole number more than zero, got "0"`,
+		},
 		{
 			name:          "unknown studio-mcp flag",
 			args:          []string{"--unknown", "echo", "hello"},
@@ -76,7 +197,7 @@ func TestParseArgs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			debug, version, command, err := parseArgs(tt.args)
+			opts, err := parseArgs(tt.args)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -85,30 +206,43 @@ func TestParseArgs(t *testing.T) {
 			}
 
 			assert.NoError(t, err)
-			assert.Equal(t, tt.expectedDebug, debug)
-			assert.Equal(t, tt.expectedVersion, version)
-			assert.Equal(t, tt.expectedCommand, command)
+			assert.Equal(t, tt.expectedDebug, opts.Debug)
+			assert.Equal(t, tt.expectedVersion, opts.Version)
+			assert.Equal(t, tt.expectedConfig, opts.Config)
+			assert.Equal(t, tt.expectedScripts, opts.ScriptsDir)
+			assert.Equal(t, tt.expectedMake, opts.Makefile)
+			assert.Equal(t, tt.expectedNPM, opts.NPMScripts)
+			assert.Equal(t, tt.expectedTool, opts.Tool)
+			assert.Equal(t, tt.expectedCommand, opts.Command)
 		})
 	}
 }
 
+func TestParseOutputLimits(t *testing.T) {
+	opts, err := parseArgs([]string{"--max-output-lines", "200", "--max-output-bytes=20000", "find", "{{dir}}"})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, opts.MaxOutputLines)
+	assert.Equal(t, 20000, opts.MaxOutputBytes)
+	assert.Equal(t, []string{"find", "{{dir}}"}, opts.Command)
+}
+
 func TestVersionFlagParsing(t *testing.T) {
 	t.Run("identifies version flag correctly", func(t *testing.T) {
-		debug, version, command, err := parseArgs([]string{"--version"})
+		opts, err := parseArgs([]string{"--version"})
 		assert.NoError(t, err)
-		assert.False(t, debug)
-		assert.True(t, version)
-		assert.Empty(t, command)
+		assert.False(t, opts.Debug)
+		assert.True(t, opts.Version)
+		assert.Empty(t, opts.Command)
 	})
 }
 
 func TestEmptyArgs(t *testing.T) {
 	t.Run("handles empty args", func(t *testing.T) {
-		debug, version, command, err := parseArgs([]string{})
+		opts, err := parseArgs([]string{})
 		assert.NoError(t, err)
-		assert.False(t, debug)
-		assert.False(t, version)
-		assert.Empty(t, command)
+		assert.False(t, opts.Debug)
+		assert.False(t, opts.Version)
+		assert.Empty(t, opts.Command)
 	})
 }
 
@@ -117,22 +251,129 @@ func TestSayCommandWithVoiceFlag(t *testing.T) {
 	t.Run("say command with -v flag should not be parsed as studio-mcp flag", func(t *testing.T) {
 		args := []string{"say", "-v", "siri", "{{speech#A very concise message to say out loud to the user}}"}
 
-		debug, version, command, err := parseArgs(args)
+		opts, err := parseArgs(args)
 
 		assert.NoError(t, err)
-		assert.False(t, debug)
-		assert.False(t, version)
-		assert.Equal(t, args, command)
+		assert.False(t, opts.Debug)
+		assert.False(t, opts.Version)
+		assert.Equal(t, args, opts.Command)
 	})
 
 	t.Run("debug flag followed by say command with -v flag", func(t *testing.T) {
 		args := []string{"--debug", "say", "-v", "siri", "{{speech#message}}"}
 
-		debug, version, command, err := parseArgs(args)
+		opts, err := parseArgs(args)
 
 		assert.NoError(t, err)
-		assert.True(t, debug)
-		assert.False(t, version)
-		assert.Equal(t, []string{"say", "-v", "siri", "{{speech#message}}"}, command)
+		assert.True(t, opts.Debug)
+		assert.False(t, opts.Version)
+		assert.Equal(t, []string{"say", "-v", "siri", "{{speech#message}}"}, opts.Command)
+	})
+}
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expected      [][]string
+		expectedError string
+	}{
+		{
+			name:     "single command",
+			args:     []string{"echo", "{{text}}"},
+			expected: [][]string{{"echo", "{{text}}"}},
+		},
+		{
+			name:     "several commands",
+			args:     []string{"echo", "{{text}}", "---", "date", "[+format]", "---", "ls", "[path]"},
+			expected: [][]string{{"echo", "{{text}}"}, {"date", "[+format]"}, {"ls", "[path]"}},
+		},
+		{
+			name:     "escaped separator is a literal argument",
+			args:     []string{"echo", `\---`, "---", "printf", `\\---`},
+			expected: [][]string{{"echo", "---"}, {"printf", `\---`}},
+		},
+		{
+			name:     "separator inside a word is left alone",
+			args:     []string{"echo", "a---b", "----"},
+			expected: [][]string{{"echo", "a---b", "----"}},
+		},
+		{
+			name:     "no command",
+			args:     []string{},
+			expected: nil,
+		},
+		{
+			name:          "trailing separator",
+			args:          []string{"echo", "---"},
+			expectedError: "tool 2 has no command",
+		},
+		{
+			name:          "doubled separator",
+			args:          []string{"echo", "---", "---", "date"},
+			expectedError: "tool 2 has no command",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, err := splitCommands(tt.args)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, commands)
+		})
+	}
+}
+
+func TestParseCommands(t *testing.T) {
+	t.Run("gives each command its own tool flags", func(t *testing.T) {
+		opts, err := parseArgs([]string{"--debug", "--name", "speak", "say", "{{text}}", "---", "--name=today", "--title", "Today", "date", "[+format]", "---", "ls"})
+		assert.NoError(t, err)
+
+		commands, err := parseCommands(opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []studio.Command{
+			{Args: []string{"say", "{{text}}"}, Options: tool.Options{Name: "speak"}},
+			{Args: []string{"date", "[+format]"}, Options: tool.Options{Name: "today", Title: "Today"}},
+			{Args: []string{"ls"}},
+		}, commands)
+	})
+
+	t.Run("applies --timeout to every tool before the first command and to one after a separator", func(t *testing.T) {
+		opts, err := parseArgs([]string{"--timeout", "30s", "curl", "{{url}}", "---", "--timeout=2m", "make", "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, opts.Timeout)
+		assert.Equal(t, tool.Options{}, opts.Tool)
+
+		commands, err := parseCommands(opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []studio.Command{
+			{Args: []string{"curl", "{{url}}"}},
+			{Args: []string{"make", "test"}, Options: tool.Options{Timeout: 2 * time.Minute}},
+		}, commands)
+	})
+
+	t.Run("rejects invalid timeouts", func(t *testing.T) {
+		_, err := parseArgs([]string{"--timeout", "soon", "sleep", "1"})
+		assert.EqualError(t, err, `--timeout: invalid duration "soon": use a number of seconds or a duration like 30s or 2m`)
+
+		_, err = parseCommands(options{Command: []string{"echo", "---", "--timeout=-1s", "date"}})
+		assert.EqualError(t, err, `--timeout: invalid duration "-1s": must be more than zero`)
+	})
+
+	t.Run("rejects unknown flags after a separator", func(t *testing.T) {
+		_, err := parseCommands(options{Command: []string{"echo", "---", "--debug", "date"}})
+		assert.ErrorContains(t, err, "unknown flag for tool 2: --debug")
+	})
+
+	t.Run("rejects tool flags without a command", func(t *testing.T) {
+		_, err := parseCommands(options{Command: []string{"echo", "---", "--name", "nothing"}})
+		assert.ErrorContains(t, err, "tool 2 has no command")
 	})
 }
@@ -0,0 +1,155 @@
+package blueprint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// ActionField is the field of a Dispatch that chooses which action runs
+const ActionField = "action"
+
+// Action is one of the blueprints a Dispatch can run
+type Action struct {
+	Name        string
+	Description string // Shown with the action in the schema; defaults to the command format
+	Blueprint   *Blueprint
+}
+
+// Dispatch serves several blueprints as a single tool. The action field chooses
+// the blueprint that runs, and the input schema is a union of the actions' schemas.
+type Dispatch struct {
+	Actions []Action
+}
+
+// NewDispatch creates a Dispatch for the given actions, which need unique names and
+// can't have a field of their own named action
+func NewDispatch(actions []Action) (*Dispatch, error) {
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("dispatch needs at least one action")
+	}
+
+	seen := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		if action.Name == "" {
+			return nil, fmt.Errorf("dispatch actions need a name")
+		}
+		if seen[action.Name] {
+			return nil, fmt.Errorf("more than one action is named %q", action.Name)
+		}
+		seen[action.Name] = true
+
+		if _, exists := action.Blueprint.GenerateInputSchema().Properties[ActionField]; exists {
+			return nil, fmt.Errorf("action %q has a field named %q, which chooses the action", action.Name, ActionField)
+		}
+	}
+	return &Dispatch{Actions: actions}, nil
+}
+
+// names returns the action names in order
+func (d *Dispatch) names() []string {
+	names := make([]string, len(d.Actions))
+	for i, action := range d.Actions {
+		names[i] = action.Name
+	}
+	return names
+}
+
+// describe returns the action's description or its command format
+func (a Action) describe() string {
+	if a.Description != "" {
+		return a.Description
+	}
+	return "`" + a.Blueprint.GetCommandFormat() + "`"
+}
+
+// BuildCommandArgs builds the command of the chosen action. Only that action's
+// parameters are validated; parameters for other actions are ignored.
+func (d *Dispatch) BuildCommandArgs(params map[string]interface{}) ([]string, error) {
+	value, exists := params[ActionField]
+	if !exists {
+		return nil, fmt.Errorf("missing required parameter: %s", ActionField)
+	}
+
+	for _, action := range d.Actions {
+		if value != action.Name {
+			continue
+		}
+
+		actionParams := make(map[string]interface{}, len(params))
+		for name, param := range params {
+			if name != ActionField {
+				actionParams[name] = param
+			}
+		}
+		return action.Blueprint.BuildCommandArgs(actionParams)
+	}
+
+	return nil, fmt.Errorf("parameter '%s' must be one of %s, got %s", ActionField, formatEnum(enumValues(d.names(), TypeString)), describeValue(value))
+}
+
+// GetBaseCommand returns the base command of the first action
+func (d *Dispatch) GetBaseCommand() string {
+	return d.Actions[0].Blueprint.GetBaseCommand()
+}
+
+// GetCommandFormat returns the command format of every action, separated by " | "
+func (d *Dispatch) GetCommandFormat() string {
+	formats := make([]string, len(d.Actions))
+	for i, action := range d.Actions {
+		formats[i] = action.Blueprint.GetCommandFormat()
+	}
+	return strings.Join(formats, " | ")
+}
+
+// GetDescription describes the tool by listing its actions
+func (d *Dispatch) GetDescription() string {
+	var description strings.Builder
+	description.WriteString("Run one of these actions:")
+	for _, action := range d.Actions {
+		description.WriteString("\n- " + action.Name + ": " + action.describe())
+	}
+	return description.String()
+}
+
+// GetInputSchema returns the input schema
+func (d *Dispatch) GetInputSchema() interface{} {
+	return d.GenerateInputSchema()
+}
+
+// GenerateInputSchema creates a discriminated union schema: the action field picks
+// one branch of oneOf, and each branch holds the fields of that action's blueprint
+func (d *Dispatch) GenerateInputSchema() *jsonschema.Schema {
+	branches := make([]*jsonschema.Schema, len(d.Actions))
+	for i, action := range d.Actions {
+		schema := action.Blueprint.GenerateInputSchema()
+
+		properties := make(map[string]*jsonschema.Schema, len(schema.Properties)+1)
+		for name, prop := range schema.Properties {
+			properties[name] = prop
+		}
+		var name any = action.Name
+		properties[ActionField] = &jsonschema.Schema{Type: "string", Const: &name}
+
+		branches[i] = &jsonschema.Schema{
+			Type:        "object",
+			Description: action.describe(),
+			Properties:  properties,
+			Required:    append([]string{ActionField}, schema.Required...),
+		}
+	}
+
+	return &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			ActionField: {
+				Type:        "string",
+				Enum:        enumValues(d.names(), TypeString),
+				Description: "The action to run",
+			},
+		},
+		Required: []string{ActionField},
+		OneOf:    branches,
+	}
+}
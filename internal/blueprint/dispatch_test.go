@@ -0,0 +1,132 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDispatch builds a dispatch between git status and git log
+func newTestDispatch(t *testing.T) *Dispatch {
+	status, err := FromArgsStrict([]string{"git", "[-C {{repo}}]", "status", "[--short]"})
+	require.NoError(t, err)
+	log, err := FromArgsStrict([]string{"git", "[-C {{repo}}]", "log", "-n", "{{count: integer}}"})
+	require.NoError(t, err)
+
+	dispatch, err := NewDispatch([]Action{
+		{Name: "status", Blueprint: status},
+		{Name: "log", Description: "Show recent commits", Blueprint: log},
+	})
+	require.NoError(t, err)
+	return dispatch
+}
+
+func TestDispatch_BuildCommandArgs(t *testing.T) {
+	dispatch := newTestDispatch(t)
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected []string
+		errMsg   string
+	}{
+		{
+			name:     "runs the chosen action",
+			params:   map[string]interface{}{"action": "status", "short": true, "repo": "/src"},
+			expected: []string{"git", "-C", "/src", "status", "--short"},
+		},
+		{
+			name:     "ignores the other actions' parameters",
+			params:   map[string]interface{}{"action": "log", "count": 3, "short": "not a boolean"},
+			expected: []string{"git", "log", "-n", "3"},
+		},
+		{
+			name:   "validates the chosen action's parameters",
+			params: map[string]interface{}{"action": "log"},
+			errMsg: "missing required parameter: count",
+		},
+		{
+			name:   "requires an action",
+			params: map[string]interface{}{"short": true},
+			errMsg: "missing required parameter: action",
+		},
+		{
+			name:   "rejects unknown actions",
+			params: map[string]interface{}{"action": "push"},
+			errMsg: `parameter 'action' must be one of status, log, got string "push"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := dispatch.BuildCommandArgs(tt.params)
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+}
+
+func TestDispatch_GenerateInputSchema(t *testing.T) {
+	schema := newTestDispatch(t).GenerateInputSchema()
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"action"}, schema.Required)
+	assert.Equal(t, []any{"status", "log"}, schema.Properties["action"].Enum)
+	require.Len(t, schema.OneOf, 2)
+
+	status, log := schema.OneOf[0], schema.OneOf[1]
+	assert.Equal(t, "`git [-C {{repo}}] status [--short]`", status.Description)
+	assert.Equal(t, any("status"), *status.Properties["action"].Const)
+	assert.Equal(t, []string{"action"}, status.Required)
+	assert.Contains(t, status.Properties, "short")
+	assert.NotContains(t, status.Properties, "count")
+
+	assert.Equal(t, "Show recent commits", log.Description)
+	assert.Equal(t, any("log"), *log.Properties["action"].Const)
+	assert.Equal(t, []string{"action", "count"}, log.Required)
+	assert.Equal(t, "integer", log.Properties["count"].Type)
+}
+
+func TestDispatch_GetDescription(t *testing.T) {
+	assert.Equal(t, "Run one of these actions:\n"+
+		"- status: `git [-C {{repo}}] status [--short]`\n"+
+		"- log: Show recent commits", newTestDispatch(t).GetDescription())
+}
+
+func TestDispatch_NewDispatch(t *testing.T) {
+	echo, err := FromArgsStrict([]string{"echo", "{{text}}"})
+	require.NoError(t, err)
+	withAction, err := FromArgsStrict([]string{"echo", "{{action}}"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		actions []Action
+		errMsg  string
+	}{
+		{name: "no actions", actions: nil, errMsg: "dispatch needs at least one action"},
+		{name: "unnamed action", actions: []Action{{Blueprint: echo}}, errMsg: "dispatch actions need a name"},
+		{
+			name:    "duplicate names",
+			actions: []Action{{Name: "say", Blueprint: echo}, {Name: "say", Blueprint: echo}},
+			errMsg:  `more than one action is named "say"`,
+		},
+		{
+			name:    "action field",
+			actions: []Action{{Name: "say", Blueprint: withAction}},
+			errMsg:  `action "say" has a field named "action", which chooses the action`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDispatch(tt.actions)
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
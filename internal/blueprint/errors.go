@@ -0,0 +1,49 @@
+package blueprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError describes a malformed template found while parsing a blueprint
+type ParseError struct {
+	Arg     int    // Index of the argument in the blueprint (0 is the base command)
+	Column  int    // 1-based byte column of the problem within the argument
+	Snippet string // The offending template text
+	Message string // What is wrong
+	Hint    string // How to fix it
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("argument %d, column %d: %s in %q", e.Arg, e.Column, e.Message, e.Snippet)
+	if e.Hint != "" {
+		msg += " (hint: " + e.Hint + ")"
+	}
+	return msg
+}
+
+// ParseErrors collects every problem found in a blueprint
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	if len(e) == 1 {
+		return "invalid blueprint: " + e[0].Error()
+	}
+
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("invalid blueprint: %d problems\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// fieldError describes why a tag couldn't be parsed as a field
+type fieldError struct {
+	Message string
+	Hint    string
+}
+
+// newFieldError creates a fieldError with a formatted message
+func newFieldError(hint, format string, args ...interface{}) *fieldError {
+	return &fieldError{Message: fmt.Sprintf(format, args...), Hint: hint}
+}
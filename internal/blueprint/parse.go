@@ -2,17 +2,42 @@ package blueprint
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"unicode"
 )
 
-// FromArgs creates a new Blueprint from command arguments using tokenization
+// fieldNamePattern matches field names that can't be mistaken for other template syntax
+var fieldNamePattern = regexp.MustCompile(`^[^\s"'\\{}\[\]]+$`)
+
+// FromArgs creates a new Blueprint from command arguments using tokenization.
+// Malformed templates are kept as literal text; use FromArgsStrict to report them.
 func FromArgs(args []string) (*Blueprint, error) {
+	bp, _, err := parseArgs(args)
+	return bp, err
+}
+
+// FromArgsStrict creates a new Blueprint like FromArgs, but fails with ParseErrors
+// pointing at each malformed template instead of treating it as literal text
+func FromArgsStrict(args []string) (*Blueprint, error) {
+	bp, problems, err := parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return bp, nil
+}
+
+// parseArgs tokenizes command arguments, collecting any problems with their templates
+func parseArgs(args []string) (*Blueprint, ParseErrors, error) {
 	if len(args) == 0 {
-		return nil, fmt.Errorf("cannot create blueprint: no command provided")
+		return nil, nil, fmt.Errorf("cannot create blueprint: no command provided")
 	}
 
 	if strings.TrimSpace(args[0]) == "" {
-		return nil, fmt.Errorf("cannot create blueprint: empty command provided")
+		return nil, nil, fmt.Errorf("cannot create blueprint: empty command provided")
 	}
 
 	bp := &Blueprint{
@@ -21,41 +46,73 @@ func FromArgs(args []string) (*Blueprint, error) {
 	}
 
 	// Tokenize each shell word
+	var problems ParseErrors
 	for i, arg := range args {
-		tokens := tokenizeShellWord(arg)
+		tokens, wordProblems := tokenizeWord(arg)
 		bp.ShellWords[i] = tokens
+		for _, problem := range wordProblems {
+			problem.Arg = i
+			problems = append(problems, problem)
+		}
 	}
+	problems = append(problems, resolveFields(bp, args)...)
 
-	return bp, nil
+	return bp, problems, nil
 }
 
 // tokenizeShellWord tokenizes a single shell word into tokens
 func tokenizeShellWord(word string) []Token {
+	tokens, _ := tokenizeWord(word)
+	return tokens
+}
+
+// tokenizeWord tokenizes a single shell word into tokens, reporting malformed
+// templates with their column in the word. Malformed templates become text tokens.
+func tokenizeWord(word string) ([]Token, ParseErrors) {
 	// Parse mixed content
 	tokens := []Token{}
+	var problems ParseErrors
 	pos := 0
 
 	for pos < len(word) {
 		templateStart := findNextTemplate(word, pos)
 		if templateStart == nil {
 			// No more templates, add remaining text
-			if pos < len(word) {
-				tokens = append(tokens, TextToken{Value: word[pos:]})
-			}
+			tokens = append(tokens, TextToken{Value: unescapeText(word[pos:])})
+			break
+		}
+
+		if templateStart.End == -1 {
+			// Malformed template - treat rest as text
+			problems = append(problems, unclosedTemplate(word, templateStart))
+			tokens = append(tokens, TextToken{Value: unescapeText(word[pos:])})
 			break
 		}
 
 		// Add text before template
 		if templateStart.Start > pos {
-			tokens = append(tokens, TextToken{Value: word[pos:templateStart.Start]})
+			tokens = append(tokens, TextToken{Value: unescapeRange(word, pos, templateStart.Start)})
 		}
 
 		// Parse template
 		templateText := word[templateStart.Start:templateStart.End]
-		if token := parseField(templateText); token != nil {
+		token, err := parseField(templateText)
+		// A group of several words would render as one argument inside a bigger word
+		if group, ok := token.(GroupToken); ok && len(group.Words) > 1 && (templateStart.Start > 0 || templateStart.End < len(word)) {
+			token, err = nil, newFieldError("put the group in an argument of its own", "a group inside a word can only have one word")
+		}
+		if err != nil {
+			problems = append(problems, &ParseError{
+				Column:  templateStart.Start + 1,
+				Snippet: templateText,
+				Message: err.Message,
+				Hint:    err.Hint,
+			})
+		}
+		if token != nil {
 			tokens = append(tokens, token)
 		} else {
-			tokens = append(tokens, TextToken{Value: templateText})
+			tokens = append(tokens, TextToken{Value: unescapeText(templateText)})
 		}
 
 		pos = templateStart.End
@@ -66,13 +123,81 @@ func tokenizeShellWord(word string) []Token {
 		tokens = append(tokens, TextToken{Value: word})
 	}
 
-	return tokens
+	return tokens, problems
+}
+
+// unclosedTemplate reports a template that is missing its closing marker
+func unclosedTemplate(word string, match *templateMatch) *ParseError {
+	problem := &ParseError{Column: match.Start + 1, Snippet: word[match.Start:]}
+	if match.Type == "required" {
+		problem.Message = `unclosed "{{"`
+		problem.Hint = `close the field with "}}", or escape literal braces as \{\{`
+	} else {
+		problem.Message = `unclosed "["`
+		problem.Hint = `close the optional field with "]", or escape a literal bracket as \[`
+	}
+	return problem
+}
+
+// isBracket reports whether a character is a bracket or brace, which a backslash escapes
+func isBracket(c byte) bool {
+	return c == '[' || c == ']' || c == '{' || c == '}'
+}
+
+// isEscape reports whether the backslash at text[i] escapes the character after it.
+// Backslashes only escape brackets and braces, and each other in a run that ends at
+// one, so \\[ is a backslash before a tag while \\server and 's/\\/x/' are kept as is.
+func isEscape(text string, i int) bool {
+	if text[i] != '\\' {
+		return false
+	}
+	end := i
+	for end < len(text) && text[end] == '\\' {
+		end++
+	}
+	return end < len(text) && isBracket(text[end])
+}
+
+// indexUnescaped finds the first occurrence of substr that isn't escaped with a backslash
+func indexUnescaped(text, substr string) int {
+	for i := 0; i < len(text); i++ {
+		if isEscape(text, i) {
+			i++
+			continue
+		}
+		if strings.HasPrefix(text[i:], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeText replaces escape sequences like \[ and \{ with the literal characters
+func unescapeText(text string) string {
+	return unescapeRange(text, 0, len(text))
+}
+
+// unescapeRange unescapes text[start:end], reading escapes in the context of the
+// whole text so the \\ in \\[ is unescaped before the tag that follows it
+func unescapeRange(text string, start, end int) string {
+	if !strings.Contains(text[start:end], "\\") {
+		return text[start:end]
+	}
+
+	var result strings.Builder
+	for i := start; i < end; i++ {
+		if isEscape(text, i) && i+1 < end {
+			i++
+		}
+		result.WriteByte(text[i])
+	}
+	return result.String()
 }
 
 // templateMatch represents a found template in the text
 type templateMatch struct {
 	Start int
-	End   int
+	End   int // -1 when the template is never closed
 	Type  string
 }
 
@@ -80,8 +205,8 @@ type templateMatch struct {
 func findNextTemplate(word string, startPos int) *templateMatch {
 	remaining := word[startPos:]
 
-	requiredStart := strings.Index(remaining, "{{")
-	optionalStart := strings.Index(remaining, "[")
+	requiredStart := indexUnescaped(remaining, "{{")
+	optionalStart := indexUnescaped(remaining, "[")
 
 	// Find the closest template start
 	var nextStart int
@@ -100,23 +225,20 @@ func findNextTemplate(word string, startPos int) *templateMatch {
 	absoluteStart := startPos + nextStart
 
 	// Find template end
-	var endMarker string
-	var markerLength int
+	var startMarker, endMarker string
 	if templateType == "required" {
-		endMarker = "}}"
-		markerLength = 2
+		startMarker, endMarker = "{{", "}}"
 	} else {
-		endMarker = "]"
-		markerLength = 1
+		startMarker, endMarker = "[", "]"
 	}
 
-	endIndex := strings.Index(remaining[nextStart:], endMarker)
+	contentStart := nextStart + len(startMarker)
+	endIndex := tagSections(remaining[contentStart:], endMarker).End
 	if endIndex == -1 {
-		// Malformed template - treat rest as text by returning no match
-		return nil
+		return &templateMatch{Start: absoluteStart, End: -1, Type: templateType}
 	}
 
-	absoluteEnd := absoluteStart + endIndex + markerLength
+	absoluteEnd := startPos + contentStart + endIndex + len(endMarker)
 
 	return &templateMatch{
 		Start: absoluteStart,
@@ -125,8 +247,9 @@ func findNextTemplate(word string, startPos int) *templateMatch {
 	}
 }
 
-// parseField parses a field enclosed in {{ }} or [ ]
-func parseField(field string) Token {
+// parseField parses a field enclosed in {{ }} or [ ]. A nil token means the tag is
+// malformed; an error alongside a token is a problem only reported in strict mode.
+func parseField(field string) (Token, *fieldError) {
 	var content string
 	var required bool
 
@@ -138,7 +261,12 @@ func parseField(field string) Token {
 		content = field[1 : len(field)-1] // Remove [ ]
 		required = false
 	} else {
-		return nil // Not a valid field
+		return nil, newFieldError("", "not a template tag") // Not a valid field
+	}
+
+	// Optional tags containing required fields are groups of shell words
+	if !required && tagSections(content, "").Group {
+		return parseGroup(content)
 	}
 
 	// Parse content for name, description, and modifiers
@@ -146,28 +274,77 @@ func parseField(field string) Token {
 	isArray := false
 	var originalFlag string
 
-	// Check for description (split on #)
-	parts := strings.SplitN(content, "#", 2)
-	name = strings.TrimSpace(parts[0])
+	// Check for description (split on #), default (split on =) and type annotation (split on :)
+	marks := tagSections(content, "")
+	head := content
+	if marks.Hash != -1 {
+		head = content[:marks.Hash]
+		description = unescapeText(strings.TrimSpace(content[marks.Hash+1:]))
+	}
+
+	var defaultValue string
+	if marks.Equals != -1 {
+		defaultValue = unescapeText(strings.TrimSpace(head[marks.Equals+1:]))
+		// A flag like [--format=json] or [--depth=1] is a literal flag rather than a
+		// default; flag defaults have spaces around the "=", like [--color = true]
+		if strings.HasPrefix(strings.TrimSpace(head), "-") && !spacedEquals(head, marks.Equals) {
+			defaultValue = ""
+		} else {
+			if required {
+				return nil, newFieldError("make the field optional, e.g. [name = value]", "required fields can't have a default")
+			}
+			if defaultValue == "" {
+				return nil, newFieldError(`put a value after the "=" or remove it`, "empty default value")
+			}
+			head = head[:marks.Equals]
+		}
+	}
+
+	var spec typeSpec
+	if marks.Colon != -1 {
+		var err *fieldError
+		if spec, err = parseTypeSpec(head[marks.Colon+1:]); err != nil {
+			return nil, err
+		}
+		head = head[:marks.Colon]
+	}
+	name = strings.TrimSpace(head)
 
 	// If name is empty, this is not a valid field (e.g., {{}})
 	if name == "" {
-		return nil
+		return nil, newFieldError("name the field, e.g. {{text}} or [text]", "empty field name")
 	}
 
-	if len(parts) > 1 {
-		description = strings.TrimSpace(parts[1])
+	// Check for array notation (...) with an optional join separator (e.g. {{tags...,}})
+	var separator string
+	if index := strings.Index(name, "..."); index != -1 {
+		isArray = true
+		separator = strings.TrimSpace(name[index+3:])
+		if len(separator) >= 2 && strings.HasPrefix(separator, `"`) && strings.HasSuffix(separator, `"`) {
+			separator = separator[1 : len(separator)-1]
+		} else if strings.ContainsAny(separator, ` "`) {
+			return nil, newFieldError(`quote separators containing spaces, e.g. {{items..." "}}`, "invalid array separator %q", separator)
+		}
+		name = strings.TrimSpace(name[:index])
+		if name == "" {
+			return nil, newFieldError("name the field, e.g. {{items...}}", "empty field name")
+		}
 	}
 
-	// Check for array notation (...)
-	if strings.HasSuffix(name, "...") {
-		isArray = true
-		name = strings.TrimSuffix(name, "...")
-		name = strings.TrimSpace(name)
+	var problem *fieldError
+	if required && strings.HasPrefix(name, "-") {
+		problem = newFieldError(fmt.Sprintf("use [%s] for an optional boolean flag", name), "flags can't be required")
 	}
 
 	// Check for boolean flag (starts with - or --)
 	if !required && (strings.HasPrefix(name, "-") || strings.HasPrefix(name, "--")) {
+		// Flags only ever render themselves, so they can't carry a value type
+		if (spec.Type != "" && spec.Type != TypeBoolean) || len(spec.Choices) > 0 || spec.Pattern != "" {
+			return nil, newFieldError("drop the dashes for a value field, or use a group like [--level {{level: integer}}]", "flags can't have a type, choices or pattern")
+		}
+		if isArray {
+			problem = newFieldError(`remove the "..."`, "flags can't be arrays")
+		}
 		originalFlag = name
 		name = strings.TrimLeft(name, "-")
 		if description == "" {
@@ -175,11 +352,293 @@ func parseField(field string) Token {
 		}
 	}
 
-	return FieldToken{
+	// Literal flags like [--format=oneline] keep their value in the name
+	if checkName, _, _ := strings.Cut(name, "="); problem == nil && !fieldNamePattern.MatchString(checkName) {
+		problem = newFieldError("field names can't contain spaces, quotes, backslashes, brackets or braces", "invalid field name %q", name)
+	}
+
+	token := FieldToken{
 		Name:         name,
 		Description:  description,
 		Required:     required,
 		IsArray:      isArray,
+		Separator:    separator,
 		OriginalFlag: originalFlag,
+		Type:         spec.Type,
+		Choices:      spec.Choices,
+		Pattern:      spec.Pattern,
+		Default:      defaultValue,
+	}
+
+	// Defaults must satisfy the field's own type and constraints
+	if value, ok := token.defaultValue(); ok {
+		if err := validateParam(name, value, fieldSchema(token)); err != nil {
+			return nil, newFieldError("", "invalid default: %v", err)
+		}
+	}
+
+	return token, problem
+}
+
+// spacedEquals reports whether there's a space on either side of the "=" at index i
+func spacedEquals(head string, i int) bool {
+	return (i > 0 && unicode.IsSpace(rune(head[i-1]))) || (i+1 < len(head) && unicode.IsSpace(rune(head[i+1])))
+}
+
+// parseGroup parses the content of an optional group such as [-v {{voice}}]
+func parseGroup(content string) (Token, *fieldError) {
+	words := splitGroupWords(content)
+	group := GroupToken{Words: make([][]Token, len(words))}
+
+	var problem *fieldError
+	hasField := false
+	for i, word := range words {
+		tokens, problems := tokenizeWord(word)
+		if len(problems) > 0 && problem == nil {
+			problem = &fieldError{Message: problems[0].Message, Hint: problems[0].Hint}
+		}
+		group.Words[i] = tokens
+		for _, token := range tokens {
+			switch token.(type) {
+			case FieldToken:
+				hasField = true
+			case GroupToken:
+				return nil, newFieldError("", "groups can't be nested")
+			}
+		}
+	}
+
+	// A group needs at least one field to decide whether it's rendered
+	if !hasField {
+		if problem != nil {
+			return nil, problem
+		}
+		return nil, newFieldError(`add a {{field}} to the group, or escape literal brackets as \[`, "group has no fields")
+	}
+
+	// Only one array can decide how often the group repeats
+	arrays := 0
+	walkFields(group.Words, func(field FieldToken, _ bool) {
+		if field.IsArray {
+			arrays++
+		}
+	})
+	if arrays > 1 && problem == nil {
+		problem = newFieldError("split the group so each has one array field", "groups can only repeat one array field")
+	}
+
+	return group, problem
+}
+
+// splitGroupWords splits group content on whitespace outside of {{tags}}
+func splitGroupWords(content string) []string {
+	var words []string
+	var current strings.Builder
+	inTag := false
+
+	for i := 0; i < len(content); i++ {
+		switch {
+		case !inTag && strings.HasPrefix(content[i:], "{{"):
+			inTag = true
+		case inTag && strings.HasPrefix(content[i:], "}}"):
+			inTag = false
+			current.WriteString("}}")
+			i++
+			continue
+		case !inTag && (content[i] == ' ' || content[i] == '\t'):
+			if current.Len() > 0 {
+				words = append(words, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteByte(content[i])
+	}
+
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+	return words
+}
+
+// tagMarks holds the positions of the separators within a tag's content
+type tagMarks struct {
+	Colon  int  // Start of the type spec
+	Equals int  // Start of the default value
+	Hash   int  // Start of the description
+	End    int  // Position of the closing marker
+	Group  bool // Whether the content contains nested {{fields}}
+}
+
+// tagSections scans tag content for the ':' type separator, the '=' default
+// separator, the '#' description separator and the closing marker, skipping over
+// /regex/ literals in the type spec. Positions are -1 when absent; with an empty
+// closer, End is len(text).
+func tagSections(text, closer string) tagMarks {
+	marks := tagMarks{Colon: -1, Equals: -1, Hash: -1, End: -1}
+	inRegex := false
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inRegex {
+			if c == '\\' {
+				i++ // Skip escaped character
+			} else if c == '/' {
+				inRegex = false
+			}
+			continue
+		}
+
+		// Escaped brackets never close a tag
+		if isEscape(text, i) {
+			i++
+			continue
+		}
+
+		if closer != "" && strings.HasPrefix(text[i:], closer) {
+			marks.End = i
+			return marks
+		}
+
+		// Optional groups like [-v {{voice}}] contain whole required tags, though braces
+		// in a description, like [path # e.g. {{HOME}}/x], are just text
+		if closer != "}}" && marks.Hash == -1 && strings.HasPrefix(text[i:], "{{") {
+			inner := tagSections(text[i+2:], "}}")
+			if inner.End == -1 {
+				return marks
+			}
+			marks.Group = true
+			i += 2 + inner.End + 1
+			continue
+		}
+
+		// Quoted array separators like {{dirs...":"}} may contain any character
+		if c == '"' && marks.Colon == -1 && marks.Equals == -1 && marks.Hash == -1 && strings.HasSuffix(text[:i], "...") {
+			closing := strings.IndexByte(text[i+1:], '"')
+			if closing == -1 {
+				return marks
+			}
+			i += closing + 1
+			continue
+		}
+
+		switch {
+		case c == '#' && marks.Hash == -1:
+			marks.Hash = i
+		case marks.Hash != -1 || marks.Equals != -1:
+			// Descriptions and default values are free text
+		case c == '=':
+			marks.Equals = i
+		case c == ':' && marks.Colon == -1:
+			marks.Colon = i
+		case c == '/' && marks.Colon != -1 && !marks.Group:
+			inRegex = true
+		}
+	}
+
+	if closer == "" {
+		marks.End = len(text)
+	}
+	return marks
+}
+
+// splitSpec splits a type spec on whitespace, keeping /regex/ literals whole
+func splitSpec(text string) []string {
+	var parts []string
+	var current strings.Builder
+	inRegex := false
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inRegex && c == '\\' && i+1 < len(text):
+			current.WriteByte(c)
+			i++
+			c = text[i]
+		case c == '/':
+			inRegex = !inRegex
+		case !inRegex && (c == ' ' || c == '\t'):
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteByte(c)
+	}
+
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// typeSpec holds the constraints declared after the ':' in a template tag
+type typeSpec struct {
+	Type    string
+	Choices []string
+	Pattern string
+}
+
+// typeSpecHint explains what may follow the ':' in a template tag
+const typeSpecHint = "use string, integer, number or boolean, choices like a|b|c, or a /pattern/"
+
+// parseTypeSpec parses a type annotation such as "integer", "alex|samantha|daniel" or "string /[A-Z]+/"
+func parseTypeSpec(text string) (typeSpec, *fieldError) {
+	var spec typeSpec
+	for _, part := range splitSpec(text) {
+		switch {
+		case len(part) >= 2 && strings.HasPrefix(part, "/") && strings.HasSuffix(part, "/"):
+			if spec.Pattern != "" {
+				return spec, newFieldError("combine them into one pattern", "more than one pattern")
+			}
+			spec.Pattern = part[1 : len(part)-1]
+			if spec.Pattern == "" {
+				return spec, newFieldError(typeSpecHint, "empty pattern")
+			}
+			if _, err := regexp.Compile(spec.Pattern); err != nil {
+				return spec, newFieldError("patterns use Go regexp syntax", "invalid pattern /%s/: %v", spec.Pattern, err)
+			}
+		case strings.Contains(part, "|"):
+			if spec.Choices != nil {
+				return spec, newFieldError(`separate choices with "|" and no spaces`, "more than one list of choices")
+			}
+			spec.Choices = strings.Split(part, "|")
+			for _, choice := range spec.Choices {
+				if choice == "" {
+					return spec, newFieldError(`separate choices with "|" and no spaces`, "empty choice in %q", part)
+				}
+			}
+		case isFieldType(part):
+			if spec.Type != "" {
+				return spec, newFieldError(typeSpecHint, "more than one type")
+			}
+			spec.Type = part
+		default:
+			return spec, newFieldError(typeSpecHint, "unknown type %q", part)
+		}
+	}
+
+	// Patterns only apply to strings
+	if spec.Pattern != "" && spec.Type != "" && spec.Type != TypeString {
+		return spec, newFieldError("drop the pattern or make the field a string", "patterns only apply to strings, not %s", spec.Type)
+	}
+
+	// Choices must be valid values of the declared type
+	for _, choice := range spec.Choices {
+		if !isValidLiteral(choice, spec.Type) {
+			return spec, newFieldError("", "choice %q is not a valid %s", choice, spec.Type)
+		}
+	}
+
+	return spec, nil
+}
+
+// isFieldType reports whether the given type annotation is supported
+func isFieldType(fieldType string) bool {
+	switch fieldType {
+	case TypeString, TypeInteger, TypeNumber, TypeBoolean:
+		return true
 	}
+	return false
 }
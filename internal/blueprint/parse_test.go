@@ -192,6 +192,26 @@ func TestBlueprint_GetCommandFormat(t *testing.T) {
 			args:     []string{"cp", "{{-r#recursive}}"},
 			expected: "cp {{-r}}",
 		},
+		{
+			name:     "optional group",
+			args:     []string{"say", "[-v {{voice # the voice to use}}]", "{{text}}"},
+			expected: "say [-v {{voice}}] {{text}}",
+		},
+		{
+			name:     "optional group embedded in a word",
+			args:     []string{"git", "log", "--format[={{format}}]"},
+			expected: "git log --format[={{format}}]",
+		},
+		{
+			name:     "array with join separators",
+			args:     []string{"tool", "{{tags...,}}", "[dirs...\":\"]"},
+			expected: "tool {{tags...,}} [dirs...\":\"]",
+		},
+		{
+			name:     "escaped brackets",
+			args:     []string{"jq", ".items\\[0\\]", "awk", "{print $1}"},
+			expected: "jq .items\\[0\\] awk {print $1}",
+		},
 		{
 			name:     "complicated template with mixed text and fields",
 			args:     []string{"curl", "http[s # use https]://api.com/{{endpoint#API endpoint}}", "[--verbose]"},
@@ -366,4 +386,511 @@ func TestBlueprint_FromArgsTokenization(t *testing.T) {
 		}
 		assert.Equal(t, expected, bp.ShellWords)
 	})
+	t.Run("tokenizes typed fields", func(t *testing.T) {
+		bp, err := FromArgs([]string{"seq", "{{count: integer # how many}}", "[ratio:number]", "[--force: boolean]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "seq"}},
+			{FieldToken{Name: "count", Description: "how many", Required: true, Type: "integer"}},
+			{FieldToken{Name: "ratio", Required: false, Type: "number"}},
+			{FieldToken{Name: "force", Description: "Enable --force flag", OriginalFlag: "--force", Type: "boolean"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("treats unknown types as literal text", func(t *testing.T) {
+		bp, err := FromArgs([]string{"echo", "{{count: bogus}}", "[--force: integer]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "echo"}},
+			{TextToken{Value: "{{count: bogus}}"}},
+			{TextToken{Value: "[--force: integer]"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+	t.Run("tokenizes enum choices", func(t *testing.T) {
+		bp, err := FromArgs([]string{"say", "{{voice: alex|samantha # voice to use}}"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "say"}},
+			{FieldToken{Name: "voice", Description: "voice to use", Required: true, Choices: []string{"alex", "samantha"}}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("treats invalid enum choices as literal text", func(t *testing.T) {
+		bp, err := FromArgs([]string{"nice", "{{level: integer low|high}}", "{{x: a||b}}", "[--flag: a|b]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "nice"}},
+			{TextToken{Value: "{{level: integer low|high}}"}},
+			{TextToken{Value: "{{x: a||b}}"}},
+			{TextToken{Value: "[--flag: a|b]"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+	t.Run("tokenizes regex patterns containing brackets and hashes", func(t *testing.T) {
+		bp, err := FromArgs([]string{"git", "checkout", "[branch: /^[a-z#\\/]+$/ # branch name]", "{{v: /\\d{1,3}/}}"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "git"}},
+			{TextToken{Value: "checkout"}},
+			{FieldToken{Name: "branch", Description: "branch name", Pattern: `^[a-z#\/]+$`}},
+			{FieldToken{Name: "v", Required: true, Pattern: `\d{1,3}`}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("tokenizes regex patterns containing spaces", func(t *testing.T) {
+		bp, err := FromArgs([]string{"echo", "{{title: /^[A-Z][a-z ]+$/}}"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "echo"}},
+			{FieldToken{Name: "title", Required: true, Pattern: "^[A-Z][a-z ]+$"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("treats invalid patterns as literal text", func(t *testing.T) {
+		bp, err := FromArgs([]string{"echo", "{{x: /(/}}", "{{n: integer /1/}}"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "echo"}},
+			{TextToken{Value: "{{x: /(/}}"}},
+			{TextToken{Value: "{{n: integer /1/}}"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+	t.Run("tokenizes default values", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "[format = json # output format]", "[url = http://localhost:8080]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "tool"}},
+			{FieldToken{Name: "format", Description: "output format", Default: "json"}},
+			{FieldToken{Name: "url", Default: "http://localhost:8080"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("keeps flags with inline values as literal flags", func(t *testing.T) {
+		bp, err := FromArgs([]string{"git", "[--format=oneline]", "[--depth=1]", "[-j=0]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "git"}},
+			{FieldToken{Name: "format=oneline", Description: "Enable --format=oneline flag", OriginalFlag: "--format=oneline"}},
+			{FieldToken{Name: "depth=1", Description: "Enable --depth=1 flag", OriginalFlag: "--depth=1"}},
+			{FieldToken{Name: "j=0", Description: "Enable -j=0 flag", OriginalFlag: "-j=0"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+
+		// Values that look like booleans are still only added when the flag is on
+		args, err := bp.BuildCommandArgs(map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"git"}, args)
+
+		args, err = bp.BuildCommandArgs(map[string]any{"depth=1": true, "j=0": true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"git", "--depth=1", "-j=0"}, args)
+	})
+
+	t.Run("treats invalid defaults as literal text", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "{{format = json}}", "[count: integer = many]", "[voice: alex|daniel = siri]", "[x = ]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "tool"}},
+			{TextToken{Value: "{{format = json}}"}},
+			{TextToken{Value: "[count: integer = many]"}},
+			{TextToken{Value: "[voice: alex|daniel = siri]"}},
+			{TextToken{Value: "[x = ]"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+	t.Run("tokenizes optional groups", func(t *testing.T) {
+		bp, err := FromArgs([]string{"say", "[-v {{voice # the voice to use}}]", "{{text}}"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "say"}},
+			{GroupToken{Words: [][]Token{
+				{TextToken{Value: "-v"}},
+				{FieldToken{Name: "voice", Description: "the voice to use", Required: true}},
+			}}},
+			{FieldToken{Name: "text", Required: true}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("tokenizes braces in optional field descriptions as text", func(t *testing.T) {
+		bp, err := FromArgsStrict([]string{"ls", "[path # e.g. {{HOME}}/x]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "ls"}},
+			{FieldToken{Name: "path", Description: "e.g. {{HOME}}/x"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("tokenizes optional groups embedded in a word", func(t *testing.T) {
+		bp, err := FromArgs([]string{"git", "log", "--format[={{format: oneline|short}}]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "git"}},
+			{TextToken{Value: "log"}},
+			{
+				TextToken{Value: "--format"},
+				GroupToken{Words: [][]Token{{
+					TextToken{Value: "="},
+					FieldToken{Name: "format", Required: true, Choices: []string{"oneline", "short"}},
+				}}},
+			},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+	t.Run("tokenizes array join separators", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "--tags", "{{tags..., # tags}}", "[dirs...\":\"]", "[ids...\", \": integer]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "tool"}},
+			{TextToken{Value: "--tags"}},
+			{FieldToken{Name: "tags", Description: "tags", Required: true, IsArray: true, Separator: ","}},
+			{FieldToken{Name: "dirs", IsArray: true, Separator: ":"}},
+			{FieldToken{Name: "ids", IsArray: true, Separator: ", ", Type: "integer"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+	t.Run("tokenizes escaped brackets and braces as literal text", func(t *testing.T) {
+		bp, err := FromArgs([]string{"jq", ".items\\[0\\]", "awk", "'\\{\\{print $1}}'", "grep", "\\\\\\[a-z]+"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "jq"}},
+			{TextToken{Value: ".items[0]"}},
+			{TextToken{Value: "awk"}},
+			{TextToken{Value: "'{{print $1}}'"}},
+			{TextToken{Value: "grep"}},
+			{TextToken{Value: "\\[a-z]+"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("tokenizes escapes next to fields", func(t *testing.T) {
+		bp, err := FromArgs([]string{"jq", ".items\\[{{index: integer}}\\]", "[filter = .a\\[0\\] # jq filter]"})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "jq"}},
+			{
+				TextToken{Value: ".items["},
+				FieldToken{Name: "index", Required: true, Type: "integer"},
+				TextToken{Value: "]"},
+			},
+			{FieldToken{Name: "filter", Description: "jq filter", Default: ".a[0]"}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+
+	t.Run("leaves other backslashes alone", func(t *testing.T) {
+		bp, err := FromArgs([]string{"printf", "a\\nb\\t", `a\\nb`, `s/\\/\//g`, `\\server\share`, `C:\\`})
+		require.NoError(t, err)
+
+		expected := [][]Token{
+			{TextToken{Value: "printf"}},
+			{TextToken{Value: "a\\nb\\t"}},
+			{TextToken{Value: `a\\nb`}},
+			{TextToken{Value: `s/\\/\//g`}},
+			{TextToken{Value: `\\server\share`}},
+			{TextToken{Value: `C:\\`}},
+		}
+		assert.Equal(t, expected, bp.ShellWords)
+	})
+}
+
+func TestBlueprint_GetCommandFormatRoundTrip(t *testing.T) {
+	args := []string{
+		"tool",
+		".items\\[0\\]",
+		"\\{\\{literal}}",
+		"C:\\\\{{file}}",
+		"\\\\[a-z]+",
+		`s/\\/\//g`,
+		`\\server\{{share}}`,
+		"awk '{print $1}'",
+		"prefix{{text}}\\[suffix\\]",
+		"[-v {{voice}}]",
+	}
+
+	bp, err := FromArgs(args)
+	require.NoError(t, err)
+
+	for i, tokens := range bp.ShellWords {
+		display := bp.renderTokensForDisplay(tokens)
+		reparsed := tokenizeShellWord(display)
+		assert.Equal(t, tokens, reparsed, "word %d displayed as %q", i, display)
+	}
+}
+
+func TestBlueprint_FromArgsStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		arg     int
+		column  int
+		snippet string
+		message string
+		hint    string
+	}{
+		{
+			name:    "unclosed required field",
+			args:    []string{"echo", "hello", "pre{{incomplete"},
+			arg:     2,
+			column:  4,
+			snippet: "{{incomplete",
+			message: `unclosed "{{"`,
+			hint:    `close the field with "}}"`,
+		},
+		{
+			name:    "unclosed optional field",
+			args:    []string{"ls", "[-la"},
+			arg:     1,
+			column:  1,
+			snippet: "[-la",
+			message: `unclosed "["`,
+			hint:    `\[`,
+		},
+		{
+			name:    "empty name",
+			args:    []string{"echo", "{{ # nothing}}"},
+			arg:     1,
+			column:  1,
+			snippet: "{{ # nothing}}",
+			message: "empty field name",
+		},
+		{
+			name:    "array boolean flag",
+			args:    []string{"ls", "[-v...]"},
+			arg:     1,
+			column:  1,
+			snippet: "[-v...]",
+			message: "flags can't be arrays",
+		},
+		{
+			name:    "invalid characters in name",
+			args:    []string{"echo", "--text={{my text}}"},
+			arg:     1,
+			column:  8,
+			snippet: "{{my text}}",
+			message: `invalid field name "my text"`,
+		},
+		{
+			name:    "required flag",
+			args:    []string{"cp", "{{-r}}"},
+			arg:     1,
+			column:  1,
+			snippet: "{{-r}}",
+			message: "flags can't be required",
+			hint:    "use [-r]",
+		},
+		{
+			name:    "unknown type",
+			args:    []string{"sleep", "{{seconds: int}}"},
+			arg:     1,
+			column:  1,
+			snippet: "{{seconds: int}}",
+			message: `unknown type "int"`,
+		},
+		{
+			name:    "pattern on a number",
+			args:    []string{"sleep", "{{seconds: integer /[0-9]+/}}"},
+			arg:     1,
+			column:  1,
+			message: "patterns only apply to strings, not integer",
+		},
+		{
+			name:    "invalid default",
+			args:    []string{"head", "-n", "[lines: integer = ten]"},
+			arg:     2,
+			column:  1,
+			message: `invalid default: parameter 'lines' must be an integer`,
+		},
+		{
+			name:    "group of several words inside a word",
+			args:    []string{"say", "pre[-v {{voice}}]"},
+			arg:     1,
+			column:  4,
+			snippet: "[-v {{voice}}]",
+			message: "a group inside a word can only have one word",
+			hint:    "an argument of its own",
+		},
+		{
+			name:    "problem inside a group",
+			args:    []string{"say", "[-v {{voice: nope}}]"},
+			arg:     1,
+			column:  1,
+			message: `unknown type "nope"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp, err := FromArgsStrict(tt.args)
+			require.Error(t, err)
+			assert.Nil(t, bp)
+
+			var problems ParseErrors
+			require.ErrorAs(t, err, &problems)
+			require.Len(t, problems, 1)
+
+			problem := problems[0]
+			assert.Equal(t, tt.arg, problem.Arg)
+			assert.Equal(t, tt.column, problem.Column)
+			if tt.snippet != "" {
+				assert.Equal(t, tt.snippet, problem.Snippet)
+			}
+			assert.Contains(t, problem.Message, tt.message)
+			assert.Contains(t, problem.Hint, tt.hint)
+
+			// Lenient parsing still accepts the blueprint
+			_, err = FromArgs(tt.args)
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("accepts valid blueprints", func(t *testing.T) {
+		bp, err := FromArgsStrict([]string{"git", "log", "[--oneline]", "[--format=short]", "[-n {{count: integer}}]", `\{\{.Name}}`, "[ref = HEAD]"})
+		require.NoError(t, err)
+		assert.Equal(t, "git log [--oneline] [--format=short] [-n {{count}}] \\{{.Name}} [ref]", bp.GetCommandFormat())
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		_, err := FromArgsStrict([]string{"echo", "{{}}", "ok", "{{a}}{{b"})
+		require.Error(t, err)
+
+		var problems ParseErrors
+		require.ErrorAs(t, err, &problems)
+		require.Len(t, problems, 2)
+		assert.Equal(t, 1, problems[0].Arg)
+		assert.Equal(t, 3, problems[1].Arg)
+		assert.Equal(t, 6, problems[1].Column)
+
+		assert.Equal(t, "invalid blueprint: 2 problems\n"+
+			`  argument 1, column 1: empty field name in "{{}}" (hint: name the field, e.g. {{text}} or [text])`+"\n"+
+			`  argument 3, column 6: unclosed "{{" in "{{b" (hint: close the field with "}}", or escape literal braces as \{\{)`,
+			err.Error())
+	})
+}
+
+func TestBlueprint_RepeatedFields(t *testing.T) {
+	conflicts := []struct {
+		name    string
+		args    []string
+		arg     int
+		column  int
+		message string
+	}{
+		{
+			name:    "array and single value",
+			args:    []string{"cp", "{{files...}}", "--log={{files}}"},
+			arg:     2,
+			column:  7,
+			message: `field "files" is a single value here but an array in argument 1`,
+		},
+		{
+			name:    "flag and value",
+			args:    []string{"ls", "[--all]", "{{all}}"},
+			arg:     2,
+			column:  1,
+			message: `field "all" is a single value here but a flag in argument 1`,
+		},
+		{
+			name:    "different types across dash and underscore names",
+			args:    []string{"head", "-n", "{{line-count: integer}}", "--max={{line_count: number}}"},
+			arg:     3,
+			column:  7,
+			message: `field "line_count" is declared as "number" here but as "integer" in argument 2`,
+		},
+		{
+			name:    "different choices",
+			args:    []string{"say", "[-v {{voice: alex|daniel}}]", "[--again {{voice: alex|samantha}}]"},
+			arg:     2,
+			column:  1,
+			message: `declared as "alex|samantha" here but as "alex|daniel" in argument 1`,
+		},
+		{
+			name:    "default on a required field",
+			args:    []string{"echo", "{{greeting}}", "[greeting = hi]"},
+			arg:     2,
+			column:  1,
+			message: `field "greeting" has a default here but is required in argument 1`,
+		},
+	}
+
+	for _, tt := range conflicts {
+		t.Run("reports "+tt.name, func(t *testing.T) {
+			_, err := FromArgsStrict(tt.args)
+			require.Error(t, err)
+
+			var problems ParseErrors
+			require.ErrorAs(t, err, &problems)
+			require.Len(t, problems, 1)
+			assert.Equal(t, tt.arg, problems[0].Arg)
+			assert.Equal(t, tt.column, problems[0].Column)
+			assert.Contains(t, problems[0].Message, tt.message)
+		})
+	}
+
+	t.Run("bare uses share the declared type", func(t *testing.T) {
+		bp, err := FromArgsStrict([]string{"seq", "[--step {{step}}]", "{{step: integer 1|2 # step size}}"})
+		require.NoError(t, err)
+
+		schema := bp.GenerateInputSchema()
+		require.Contains(t, schema.Properties, "step")
+		assert.Equal(t, "integer", schema.Properties["step"].Type)
+		assert.Equal(t, []any{1.0, 2.0}, schema.Properties["step"].Enum)
+		assert.Equal(t, "step size", schema.Properties["step"].Description)
+		assert.Equal(t, []string{"step"}, schema.Required)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"step": 2.0})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"seq", "--step", "2", "2"}, args)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"step": 3.0})
+		assert.ErrorContains(t, err, "must be one of 1, 2")
+	})
+
+	t.Run("bare uses share the declared default", func(t *testing.T) {
+		bp, err := FromArgsStrict([]string{"convert", "[-f {{format}}]", "[format = png]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"convert", "-f", "png", "png"}, args)
+	})
+
+	t.Run("arrays may join differently in each use", func(t *testing.T) {
+		bp, err := FromArgsStrict([]string{"echo", "{{tags...,}}", "{{tags...}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"tags": []interface{}{"a", "b"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"echo", "a,b", "a", "b"}, args)
+	})
+
+	t.Run("lenient parsing keeps the first declaration", func(t *testing.T) {
+		bp, err := FromArgs([]string{"head", "{{lines: integer}}", "{{lines: number}}"})
+		require.NoError(t, err)
+		assert.Equal(t, "integer", bp.GenerateInputSchema().Properties["lines"].Type)
+	})
 }
@@ -1,8 +1,12 @@
 package blueprint
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 )
 
 // normalizeFieldName converts field names to use underscores instead of dashes
@@ -45,23 +49,20 @@ func (bp *Blueprint) buildCommandArgsTokenized(params map[string]interface{}) ([
 		}
 	}
 
+	// Some clients send null for optional arguments they leave out
+	params = omitNulls(params, inputSchema)
+
 	// Validate parameter types
 	for name, param := range params {
 		if schema, exists := inputSchema.Properties[normalizeFieldName(name)]; exists {
-			if schema.Type == "array" {
-				// Check if it's an array type
-				switch v := param.(type) {
-				case []string:
-					// Valid
-				case []interface{}:
-					// Valid (from JSON)
-				default:
-					return nil, fmt.Errorf("parameter '%s' must be an array, got %T", name, v)
-				}
+			if err := validateParam(name, param, schema); err != nil {
+				return nil, err
 			}
 		}
 	}
 
+	params = applyDefaults(params, inputSchema)
+
 	result := []string{}
 
 	for _, shellWord := range bp.ShellWords {
@@ -79,6 +80,47 @@ func (bp *Blueprint) buildCommandArgsTokenized(params map[string]interface{}) ([
 	return result, nil
 }
 
+// omitNulls returns a copy of params without the null values of optional fields, so
+// they're treated as omitted
+func omitNulls(params map[string]interface{}, schema *jsonschema.Schema) map[string]interface{} {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	present := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		if value == nil && !required[normalizeFieldName(name)] {
+			continue
+		}
+		present[name] = value
+	}
+	return present
+}
+
+// applyDefaults returns a copy of params with schema defaults filled in for omitted fields
+func applyDefaults(params map[string]interface{}, schema *jsonschema.Schema) map[string]interface{} {
+	withDefaults := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		withDefaults[name] = value
+	}
+
+	for name, prop := range schema.Properties {
+		if prop.Default == nil {
+			continue
+		}
+		if _, exists := findParamValue(params, name); exists {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(prop.Default, &value); err == nil {
+			withDefaults[name] = value
+		}
+	}
+
+	return withDefaults
+}
+
 // renderShellWord renders a single shell word from its tokens
 func (bp *Blueprint) renderShellWord(tokens []Token, params map[string]interface{}) (bool, []string) {
 	// Check if this word contains only optional fields that are not provided
@@ -97,13 +139,17 @@ func (bp *Blueprint) renderShellWord(tokens []Token, params map[string]interface
 					allOptionalFieldsEmpty = false
 				} else {
 					// Optional field - check if it has a meaningful value
-					if bp.hasValue(value) {
+					if bp.fieldHasValue(t, value) {
 						allOptionalFieldsEmpty = false
 					}
 				}
 			} else if t.Required {
 				hasRequiredContent = true
 			}
+		case GroupToken:
+			if bp.groupHasValues(t, params) {
+				allOptionalFieldsEmpty = false
+			}
 		}
 	}
 
@@ -114,6 +160,10 @@ func (bp *Blueprint) renderShellWord(tokens []Token, params map[string]interface
 
 	// Handle special cases for single field tokens
 	if len(tokens) == 1 {
+		if groupToken, ok := tokens[0].(GroupToken); ok {
+			return bp.renderGroup(groupToken, params)
+		}
+
 		if fieldToken, ok := tokens[0].(FieldToken); ok {
 			inputSchema := bp.GenerateInputSchema()
 			// Check if this is an array field first (arrays take precedence)
@@ -128,28 +178,128 @@ func (bp *Blueprint) renderShellWord(tokens []Token, params map[string]interface
 		}
 	}
 
-	// Render as a single concatenated string
-	var parts []string
+	// Render as a concatenated string. Arrays without a separator expand the word
+	// once per item, like shell brace expansion: pre{{ids...}} -> prea preb
+	var parts [][]string
+	hasContent := false
 	for _, token := range tokens {
 		switch t := token.(type) {
 		case TextToken:
-			parts = append(parts, t.Value)
+			parts = append(parts, []string{t.Value})
+			hasContent = true
 		case FieldToken:
 			if value, exists := findParamValue(params, t.Name); exists {
-				if strValue := bp.valueToString(value); strValue != "" {
-					parts = append(parts, strValue)
+				if items, isArray := toSlice(value); isArray && t.Separator == "" {
+					parts = append(parts, bp.itemStrings(items))
+					hasContent = true
+				} else if strValue := bp.fieldValueToString(t, value); strValue != "" {
+					parts = append(parts, []string{strValue})
+					hasContent = true
 				}
 			}
+		case GroupToken:
+			if ok, words := bp.renderGroup(t, params); ok {
+				parts = append(parts, []string{strings.Join(words, " ")})
+				hasContent = true
+			}
 		}
 	}
 
-	if len(parts) > 0 {
-		return true, []string{strings.Join(parts, "")}
+	if hasContent {
+		words := expandWord(parts)
+		return len(words) > 0, words
 	}
 
 	return false, nil
 }
 
+// expandWord combines the alternatives for each part of a word into every possible word
+func expandWord(parts [][]string) []string {
+	words := []string{""}
+	for _, alternatives := range parts {
+		next := make([]string, 0, len(words)*len(alternatives))
+		for _, word := range words {
+			for _, alternative := range alternatives {
+				next = append(next, word+alternative)
+			}
+		}
+		words = next
+	}
+	return words
+}
+
+// groupHasValues checks whether every required field in a group has a value
+func (bp *Blueprint) groupHasValues(group GroupToken, params map[string]interface{}) bool {
+	hasValues := true
+	walkFields(group.Words, func(field FieldToken, _ bool) {
+		if !field.Required {
+			return
+		}
+		value, exists := findParamValue(params, field.Name)
+		if !exists || !bp.fieldHasValue(field, value) {
+			hasValues = false
+		}
+	})
+	return hasValues
+}
+
+// renderGroup renders all words of an optional group, or nothing if any of its fields are missing.
+// A group containing an array field is repeated for each item, so [--include {{paths...}}]
+// renders as --include a --include b.
+func (bp *Blueprint) renderGroup(group GroupToken, params map[string]interface{}) (bool, []string) {
+	if !bp.groupHasValues(group, params) {
+		return false, nil
+	}
+
+	arrayField, items := bp.groupArrayItems(group, params)
+	if arrayField == "" {
+		return bp.renderGroupWords(group, params)
+	}
+
+	var result []string
+	for _, item := range items {
+		itemParams := make(map[string]interface{}, len(params))
+		for name, value := range params {
+			itemParams[name] = value
+		}
+		itemParams[arrayField] = item
+		itemParams[normalizeFieldName(arrayField)] = item
+
+		if ok, words := bp.renderGroupWords(group, itemParams); ok {
+			result = append(result, words...)
+		}
+	}
+	return len(result) > 0, result
+}
+
+// renderGroupWords renders each word of a group once
+func (bp *Blueprint) renderGroupWords(group GroupToken, params map[string]interface{}) (bool, []string) {
+	var result []string
+	for _, tokens := range group.Words {
+		if ok, words := bp.renderShellWord(tokens, params); ok {
+			result = append(result, words...)
+		}
+	}
+	return len(result) > 0, result
+}
+
+// groupArrayItems finds the first array field in a group and returns its name and items
+func (bp *Blueprint) groupArrayItems(group GroupToken, params map[string]interface{}) (string, []interface{}) {
+	var name string
+	var items []interface{}
+	walkFields(group.Words, func(field FieldToken, _ bool) {
+		if name != "" || !field.IsArray {
+			return
+		}
+		if value, exists := findParamValue(params, field.Name); exists {
+			if slice, ok := toSlice(value); ok {
+				name, items = field.Name, slice
+			}
+		}
+	})
+	return name, items
+}
+
 // renderSingleOptionalField handles rendering of a single optional field token
 func (bp *Blueprint) renderSingleOptionalField(fieldToken FieldToken, params map[string]interface{}) (bool, []string) {
 	value, exists := findParamValue(params, fieldToken.Name)
@@ -158,8 +308,8 @@ func (bp *Blueprint) renderSingleOptionalField(fieldToken FieldToken, params map
 	}
 
 	inputSchema := bp.GenerateInputSchema()
-	// Check if this is a boolean flag
-	if schema, schemaExists := inputSchema.Properties[normalizeFieldName(fieldToken.Name)]; schemaExists && schema.Type == "boolean" {
+	// Check if this is a boolean flag (typed booleans render their value instead)
+	if schema, schemaExists := inputSchema.Properties[normalizeFieldName(fieldToken.Name)]; schemaExists && schema.Type == "boolean" && fieldToken.Type == "" {
 		if boolValue, ok := value.(bool); ok {
 			if boolValue {
 				// Use the original flag format if available, otherwise construct it
@@ -188,19 +338,36 @@ func (bp *Blueprint) renderArrayField(fieldToken FieldToken, params map[string]i
 		return false, nil
 	}
 
-	if arr, ok := value.([]string); ok {
-		return len(arr) > 0, arr
-	} else if arr, ok := value.([]interface{}); ok {
-		result := make([]string, 0, len(arr))
-		for _, item := range arr {
-			if str, ok := item.(string); ok {
-				result = append(result, str)
-			}
+	// A single item stands in for the array when a group is repeated per item
+	items, ok := toSlice(value)
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	result := bp.itemStrings(items)
+	if fieldToken.Separator != "" && len(result) > 0 {
+		return true, []string{strings.Join(result, fieldToken.Separator)}
+	}
+	return len(result) > 0, result
+}
+
+// itemStrings converts array items to strings, skipping nulls
+func (bp *Blueprint) itemStrings(items []interface{}) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			result = append(result, bp.valueToString(item))
 		}
-		return len(result) > 0, result
 	}
+	return result
+}
 
-	return false, nil
+// fieldValueToString converts a field value to a string, joining arrays with the field's separator
+func (bp *Blueprint) fieldValueToString(fieldToken FieldToken, value interface{}) string {
+	if items, ok := toSlice(value); ok {
+		return strings.Join(bp.itemStrings(items), fieldToken.Separator)
+	}
+	return bp.valueToString(value)
 }
 
 // hasValue checks if a value is meaningful (not empty)
@@ -219,6 +386,15 @@ func (bp *Blueprint) hasValue(value interface{}) bool {
 	}
 }
 
+// fieldHasValue checks if a field's value is meaningful. A typed boolean renders false
+// as a value, unlike a flag.
+func (bp *Blueprint) fieldHasValue(field FieldToken, value interface{}) bool {
+	if _, isBool := value.(bool); isBool && field.Type == TypeBoolean {
+		return true
+	}
+	return bp.hasValue(value)
+}
+
 // valueToString converts a value to its string representation
 func (bp *Blueprint) valueToString(value interface{}) string {
 	switch v := value.(type) {
@@ -229,6 +405,13 @@ func (bp *Blueprint) valueToString(value interface{}) string {
 			return "true"
 		}
 		return "false"
+	case float64:
+		// Avoid exponent formatting (e.g. 3e+06) for large numbers
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case json.Number:
+		return v.String()
 	default:
 		return fmt.Sprintf("%v", value)
 	}
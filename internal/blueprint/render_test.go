@@ -467,3 +467,376 @@ func TestBlueprint_BuildCommandArgsTokenized(t *testing.T) {
 		assert.Contains(t, err.Error(), "missing required parameter")
 	})
 }
+
+func TestBlueprint_BuildCommandArgsWithTypedFields(t *testing.T) {
+	t.Run("renders integers without float formatting", func(t *testing.T) {
+		bp, err := FromArgs([]string{"head", "-n", "{{count: integer}}"})
+		require.NoError(t, err)
+
+		// JSON numbers arrive as float64
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"count": float64(3000000)})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"head", "-n", "3000000"}, args)
+	})
+
+	t.Run("renders numbers in their shortest form", func(t *testing.T) {
+		bp, err := FromArgs([]string{"sleep", "{{seconds: number}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"seconds": 0.25})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sleep", "0.25"}, args)
+	})
+
+	t.Run("renders required booleans as their value", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "--enabled={{enabled: boolean}}", "[color: boolean]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"enabled": false, "color": true})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--enabled=false", "true"}, args)
+	})
+
+	t.Run("renders typed array items", func(t *testing.T) {
+		bp, err := FromArgs([]string{"kill", "{{pids...: integer}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"pids": []interface{}{float64(12), float64(345)}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"kill", "12", "345"}, args)
+	})
+
+	t.Run("treats null optional fields as omitted", func(t *testing.T) {
+		bp, err := FromArgs([]string{"ls", "[-l]", "[n: integer]", "[sort: name|size]", "[format = long]", "[path]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"l": nil, "n": nil, "sort": nil, "format": nil, "path": nil})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ls", "long"}, args)
+	})
+
+	t.Run("rejects null for required typed fields", func(t *testing.T) {
+		bp, err := FromArgs([]string{"head", "-n", "{{count: integer}}"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"count": nil})
+		assert.EqualError(t, err, "parameter 'count' must be an integer, got null")
+	})
+
+	t.Run("rejects strings for integer fields", func(t *testing.T) {
+		bp, err := FromArgs([]string{"head", "-n", "{{count: integer}}"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"count": "10"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "parameter 'count' must be an integer")
+	})
+
+	t.Run("rejects fractional values for integer fields", func(t *testing.T) {
+		bp, err := FromArgs([]string{"head", "-n", "{{count: integer}}"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"count": 1.5})
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects invalid array items", func(t *testing.T) {
+		bp, err := FromArgs([]string{"kill", "{{pids...: integer}}"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"pids": []interface{}{"12"}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "parameter 'pids[0]' must be an integer")
+	})
+}
+
+func TestBlueprint_BuildCommandArgsWithEnums(t *testing.T) {
+	t.Run("accepts a declared choice", func(t *testing.T) {
+		bp, err := FromArgs([]string{"say", "-v", "{{voice: alex|samantha|daniel}}", "hi"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"voice": "samantha"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"say", "-v", "samantha", "hi"}, args)
+	})
+
+	t.Run("rejects values outside the choices", func(t *testing.T) {
+		bp, err := FromArgs([]string{"say", "-v", "{{voice: alex|samantha|daniel}}", "hi"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"voice": "siri"})
+		assert.Error(t, err)
+		assert.Equal(t, `parameter 'voice' must be one of alex, samantha, daniel, got string "siri"`, err.Error())
+	})
+
+	t.Run("matches numeric choices", func(t *testing.T) {
+		bp, err := FromArgs([]string{"nice", "-n", "[level: integer 0|10|19]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"level": float64(10)})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"nice", "-n", "10"}, args)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"level": float64(5)})
+		assert.Error(t, err)
+	})
+
+	t.Run("checks each array item", func(t *testing.T) {
+		bp, err := FromArgs([]string{"kubectl", "get", "[kinds...: pods|services]"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"kinds": []interface{}{"pods", "nodes"}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "parameter 'kinds[1]' must be one of pods, services")
+	})
+}
+
+func TestBlueprint_BuildCommandArgsWithPatterns(t *testing.T) {
+	t.Run("accepts matching values", func(t *testing.T) {
+		bp, err := FromArgs([]string{"jira", "view", "{{ticket: /^[A-Z]+-[0-9]+$/}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"ticket": "ABC-123"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"jira", "view", "ABC-123"}, args)
+	})
+
+	t.Run("rejects values that don't match", func(t *testing.T) {
+		bp, err := FromArgs([]string{"jira", "view", "{{ticket: /^[A-Z]+-[0-9]+$/}}"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"ticket": "abc; rm -rf /"})
+		assert.Error(t, err)
+		assert.Equal(t, `parameter 'ticket' must match /^[A-Z]+-[0-9]+$/, got string "abc; rm -rf /"`, err.Error())
+	})
+
+	t.Run("checks each array item", func(t *testing.T) {
+		bp, err := FromArgs([]string{"npm", "install", "[versions...: /^[0-9]+\\.[0-9]+\\.[0-9]+$/]"})
+		require.NoError(t, err)
+
+		_, err = bp.BuildCommandArgs(map[string]interface{}{"versions": []interface{}{"1.2.3", "latest"}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "parameter 'versions[1]' must match")
+	})
+}
+
+func TestBlueprint_BuildCommandArgsWithDefaults(t *testing.T) {
+	t.Run("renders defaults for omitted fields", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "--format", "[format = json]", "[count: integer = 10]", "[paths... = src test]", "[--color = true]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--format", "json", "10", "src", "test", "--color"}, args)
+	})
+
+	t.Run("provided values override defaults", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "--format", "[format = json]", "[paths... = src test]", "[--color = true]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{
+			"format": "yaml",
+			"paths":  []interface{}{"lib"},
+			"color":  false,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--format", "yaml", "lib"}, args)
+	})
+
+	t.Run("renders defaults inside larger words", func(t *testing.T) {
+		bp, err := FromArgs([]string{"curl", "http://localhost:[port: integer = 8080]/health"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"curl", "http://localhost:8080/health"}, args)
+	})
+}
+
+func TestBlueprint_BuildCommandArgsWithGroups(t *testing.T) {
+	t.Run("renders every word of a group when its fields are provided", func(t *testing.T) {
+		bp, err := FromArgs([]string{"say", "[-v {{voice}}]", "{{text}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"voice": "Alex", "text": "hello"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"say", "-v", "Alex", "hello"}, args)
+	})
+
+	t.Run("drops the whole group when a field is omitted", func(t *testing.T) {
+		bp, err := FromArgs([]string{"say", "[-v {{voice}}]", "{{text}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"text": "hello"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"say", "hello"}, args)
+
+		args, err = bp.BuildCommandArgs(map[string]interface{}{"voice": "", "text": "hello"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"say", "hello"}, args)
+	})
+
+	t.Run("requires every field in the group", func(t *testing.T) {
+		bp, err := FromArgs([]string{"ssh", "[-i {{key}} -p {{port: integer}}]", "host"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"key": "id_rsa"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ssh", "host"}, args)
+
+		args, err = bp.BuildCommandArgs(map[string]interface{}{"key": "id_rsa", "port": float64(2222)})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ssh", "-i", "id_rsa", "-p", "2222", "host"}, args)
+	})
+
+	t.Run("renders groups embedded in a word", func(t *testing.T) {
+		bp, err := FromArgs([]string{"git", "log", "--format[={{format}}]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"format": "oneline"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"git", "log", "--format=oneline"}, args)
+
+		args, err = bp.BuildCommandArgs(map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"git", "log", "--format"}, args)
+	})
+
+	t.Run("renders typed booleans set to false", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "[--color={{color: boolean}}]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"color": false})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--color=false"}, args)
+	})
+
+	t.Run("renders optional typed booleans set to false", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "--color", "[color: boolean]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"color": false})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--color", "false"}, args)
+
+		args, err = bp.BuildCommandArgs(map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--color"}, args)
+	})
+}
+
+func TestBlueprint_BuildCommandArgsWithRepeatedGroups(t *testing.T) {
+	t.Run("repeats a flag for each array item", func(t *testing.T) {
+		bp, err := FromArgs([]string{"rg", "[--glob {{globs...}}]", "{{pattern}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{
+			"globs":   []interface{}{"*.go", "!vendor"},
+			"pattern": "TODO",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"rg", "--glob", "*.go", "--glob", "!vendor", "TODO"}, args)
+	})
+
+	t.Run("repeats joined flags for each array item", func(t *testing.T) {
+		bp, err := FromArgs([]string{"docker", "run", "[--env={{env...}}]", "{{image}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{
+			"env":   []string{"A=1", "B=2"},
+			"image": "alpine",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"docker", "run", "--env=A=1", "--env=B=2", "alpine"}, args)
+	})
+
+	t.Run("drops the group for an empty array", func(t *testing.T) {
+		bp, err := FromArgs([]string{"curl", "[-H {{headers...}}]", "{{url}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{
+			"headers": []interface{}{},
+			"url":     "https://example.com",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"curl", "https://example.com"}, args)
+	})
+
+	t.Run("handles dashed array names", func(t *testing.T) {
+		bp, err := FromArgs([]string{"curl", "[-H {{extra-headers...}}]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{
+			"extra-headers": []interface{}{"Accept: text/plain"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"curl", "-H", "Accept: text/plain"}, args)
+	})
+}
+
+func TestBlueprint_BuildCommandArgsWithJoinedArrays(t *testing.T) {
+	t.Run("joins array items into a single word", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "--tags", "{{tags..., # tags}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"tags": []interface{}{"a", "b", "c"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--tags", "a,b,c"}, args)
+	})
+
+	t.Run("joins array items inside a larger word", func(t *testing.T) {
+		bp, err := FromArgs([]string{"curl", "https://api.example.com/items/{{ids...,: integer}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"ids": []interface{}{float64(1), float64(2)}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"curl", "https://api.example.com/items/1,2"}, args)
+	})
+
+	t.Run("joins with quoted separators", func(t *testing.T) {
+		bp, err := FromArgs([]string{"env", "PATH={{dirs...\":\"}}", "which", "go"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"dirs": []string{"/usr/bin", "/bin"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"env", "PATH=/usr/bin:/bin", "which", "go"}, args)
+	})
+
+	t.Run("expands arrays without a separator inside a larger word", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "--file={{files...}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"files": []interface{}{"a.txt", "b.txt"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool", "--file=a.txt", "--file=b.txt"}, args)
+	})
+
+	t.Run("drops optional joined arrays when empty", func(t *testing.T) {
+		bp, err := FromArgs([]string{"tool", "[tags...,]"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"tags": []interface{}{}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tool"}, args)
+	})
+}
+
+func TestBlueprint_BuildCommandArgsWithEscapes(t *testing.T) {
+	t.Run("passes escaped brackets through literally", func(t *testing.T) {
+		bp, err := FromArgs([]string{"jq", ".items\\[{{index: integer}}\\].name", "{{file}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"index": float64(2), "file": "data.json"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"jq", ".items[2].name", "data.json"}, args)
+	})
+
+	t.Run("passes escaped braces through literally", func(t *testing.T) {
+		bp, err := FromArgs([]string{"docker", "inspect", "--format", "\\{\\{.State.Status}}", "{{container}}"})
+		require.NoError(t, err)
+
+		args, err := bp.BuildCommandArgs(map[string]interface{}{"container": "web"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"docker", "inspect", "--format", "{{.State.Status}}", "web"}, args)
+	})
+}
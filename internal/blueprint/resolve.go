@@ -0,0 +1,163 @@
+package blueprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldOccurrence is one use of a field in a blueprint
+type fieldOccurrence struct {
+	Field   FieldToken
+	Arg     int  // Index of the argument containing the field
+	Nth     int  // How many earlier uses of the same field are in that argument
+	InGroup bool // Whether the field is inside an optional group
+}
+
+// resolveFields checks that every use of a field agrees on what the field is, and
+// copies the type, choices, pattern and default declared by one use to the bare
+// uses elsewhere, so {{count: integer}} ... {{count}} renders count the same way
+// twice. Conflicting uses are reported; the first declaration wins the schema.
+func resolveFields(bp *Blueprint, args []string) ParseErrors {
+	var names []string
+	uses := make(map[string][]fieldOccurrence)
+
+	for i, tokens := range bp.ShellWords {
+		perArg := make(map[string]int)
+		walkFields([][]Token{tokens}, func(field FieldToken, inGroup bool) {
+			name := normalizeFieldName(field.Name)
+			if _, exists := uses[name]; !exists {
+				names = append(names, name)
+			}
+			uses[name] = append(uses[name], fieldOccurrence{Field: field, Arg: i, Nth: perArg[name], InGroup: inGroup})
+			perArg[name]++
+		})
+	}
+
+	var problems ParseErrors
+	declarations := make(map[string]FieldToken)
+	for _, name := range names {
+		occurrences := uses[name]
+		if len(occurrences) < 2 {
+			continue
+		}
+
+		first := occurrences[0]
+		var declared *fieldOccurrence
+		var required *fieldOccurrence
+		for i := range occurrences {
+			use := &occurrences[i]
+			if use.Field.Required && !use.InGroup && required == nil {
+				required = use
+			}
+
+			if i > 0 && use.Field.kind() != first.Field.kind() {
+				problems = append(problems, occurrenceError(args, use,
+					fmt.Sprintf("field %q is %s here but %s in argument %d", use.Field.Name, use.Field.kind(), first.Field.kind(), first.Arg),
+					"give each use its own name"))
+				continue
+			}
+
+			if !use.Field.declaresSpec() {
+				continue
+			}
+			if declared == nil {
+				declared = use
+				declarations[name] = use.Field
+			} else if describeSpec(use.Field) != describeSpec(declared.Field) {
+				problems = append(problems, occurrenceError(args, use,
+					fmt.Sprintf("field %q is declared as %q here but as %q in argument %d", use.Field.Name, describeSpec(use.Field), describeSpec(declared.Field), declared.Arg),
+					fmt.Sprintf("declare the field once and refer to it by name elsewhere, e.g. {{%s}}", use.Field.Name)))
+			}
+		}
+
+		if required != nil && declared != nil && declared.Field.Default != "" {
+			problems = append(problems, occurrenceError(args, declared,
+				fmt.Sprintf("field %q has a default here but is required in argument %d, so the default never applies", declared.Field.Name, required.Arg),
+				"make every use optional, or remove the default"))
+		}
+	}
+
+	// Bare uses of a field share the declared type, choices, pattern and default
+	mapFields(bp.ShellWords, func(field FieldToken) FieldToken {
+		declaration, exists := declarations[normalizeFieldName(field.Name)]
+		if !exists || field.declaresSpec() || field.kind() != declaration.kind() {
+			return field
+		}
+		field.Type = declaration.Type
+		field.Choices = declaration.Choices
+		field.Pattern = declaration.Pattern
+		field.Default = declaration.Default
+		return field
+	})
+
+	return problems
+}
+
+// kind describes how a field is rendered, for conflict messages
+func (t FieldToken) kind() string {
+	switch {
+	case t.OriginalFlag != "":
+		return "a flag"
+	case t.IsArray:
+		return "an array"
+	}
+	return "a single value"
+}
+
+// declaresSpec reports whether a field declares its own type, choices, pattern or default
+func (t FieldToken) declaresSpec() bool {
+	return t.Type != "" || len(t.Choices) > 0 || t.Pattern != "" || t.Default != ""
+}
+
+// describeSpec formats the declared type, choices, pattern and default of a field like the tag does
+func describeSpec(t FieldToken) string {
+	var parts []string
+	if t.Type != "" {
+		parts = append(parts, t.Type)
+	}
+	if len(t.Choices) > 0 {
+		parts = append(parts, strings.Join(t.Choices, "|"))
+	}
+	if t.Pattern != "" {
+		parts = append(parts, "/"+t.Pattern+"/")
+	}
+	if t.Default != "" {
+		parts = append(parts, "= "+t.Default)
+	}
+	return strings.Join(parts, " ")
+}
+
+// occurrenceError reports a problem with a use of a field at its position in the argument
+func occurrenceError(args []string, use *fieldOccurrence, message, hint string) *ParseError {
+	column, snippet := locateField(args[use.Arg], use.Field.Name, use.Nth)
+	return &ParseError{Arg: use.Arg, Column: column, Snippet: snippet, Message: message, Hint: hint}
+}
+
+// locateField finds the tag holding the nth use of a field in an argument
+func locateField(word, name string, nth int) (int, string) {
+	name = normalizeFieldName(name)
+	for pos := 0; pos < len(word); {
+		match := findNextTemplate(word, pos)
+		if match == nil || match.End == -1 {
+			break
+		}
+
+		text := word[match.Start:match.End]
+		if token, _ := parseField(text); token != nil {
+			found := false
+			walkFields([][]Token{{token}}, func(field FieldToken, _ bool) {
+				if normalizeFieldName(field.Name) == name {
+					if nth == 0 {
+						found = true
+					}
+					nth--
+				}
+			})
+			if found {
+				return match.Start + 1, text
+			}
+		}
+		pos = match.End
+	}
+	return 1, word
+}
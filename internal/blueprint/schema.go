@@ -1,6 +1,7 @@
 package blueprint
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -12,71 +13,28 @@ func (bp *Blueprint) GenerateInputSchema() *jsonschema.Schema {
 	properties := make(map[string]*jsonschema.Schema)
 	required := []string{}
 
-	// Iterate through all shell words and their tokens
-	for _, tokens := range bp.ShellWords {
-		for _, token := range tokens {
-			if fieldToken, ok := token.(FieldToken); ok {
-				// Use normalized name for schema properties (dashes to underscores)
-				normalizedName := strings.ReplaceAll(fieldToken.Name, "-", "_")
-
-				// Skip if we already have this property and it has a description
-				if existingProp, exists := properties[normalizedName]; exists {
-					if fieldToken.Description != "" && existingProp.Description == "" {
-						// Update existing property with description
-						existingProp.Description = fieldToken.Description
-					}
-					// Handle required status - if any instance is required, make it required
-					if fieldToken.Required && !contains(required, normalizedName) {
-						required = append(required, normalizedName)
-					}
-					continue
-				}
-
-				// Create new property based on token type
-				var prop *jsonschema.Schema
-
-				if fieldToken.OriginalFlag != "" {
-					// Boolean flag
-					description := fieldToken.Description
-					if description == "" {
-						description = fmt.Sprintf("Enable %s flag", fieldToken.OriginalFlag)
-					}
-					prop = &jsonschema.Schema{
-						Type:        "boolean",
-						Description: description,
-					}
-				} else if fieldToken.IsArray {
-					// Array field
-					description := fieldToken.Description
-					if description == "" {
-						description = "Additional command line arguments"
-					}
-					prop = &jsonschema.Schema{
-						Type:        "array",
-						Items:       &jsonschema.Schema{Type: "string"},
-						Description: description,
-					}
-					// Array fields follow the same required logic as other fields
-					if fieldToken.Required && !contains(required, normalizedName) {
-						required = append(required, normalizedName)
-					}
-				} else {
-					// String field
-					prop = &jsonschema.Schema{Type: "string"}
-					if fieldToken.Description != "" {
-						prop.Description = fieldToken.Description
-					}
-
-					// Add to required if the token is marked as required
-					if fieldToken.Required && !contains(required, normalizedName) {
-						required = append(required, normalizedName)
-					}
-				}
-
-				properties[normalizedName] = prop
+	// Iterate through all fields in the shell words, including those in groups
+	walkFields(bp.ShellWords, func(fieldToken FieldToken, inGroup bool) {
+		// Use normalized name for schema properties (dashes to underscores)
+		normalizedName := strings.ReplaceAll(fieldToken.Name, "-", "_")
+
+		// Handle required status - if any instance is required, make it required.
+		// Fields inside an optional group are only required by the group itself.
+		if fieldToken.Required && !inGroup && !contains(required, normalizedName) {
+			required = append(required, normalizedName)
+		}
+
+		// Skip if we already have this property and it has a description
+		if existingProp, exists := properties[normalizedName]; exists {
+			if fieldToken.Description != "" && existingProp.Description == "" {
+				// Update existing property with description
+				existingProp.Description = fieldToken.Description
 			}
+			return
 		}
-	}
+
+		properties[normalizedName] = fieldSchema(fieldToken)
+	})
 
 	schema := &jsonschema.Schema{
 		Type:       "object",
@@ -86,3 +44,71 @@ func (bp *Blueprint) GenerateInputSchema() *jsonschema.Schema {
 
 	return schema
 }
+
+// fieldSchema creates the property schema for a single field token
+func fieldSchema(fieldToken FieldToken) *jsonschema.Schema {
+	var prop *jsonschema.Schema
+
+	if fieldToken.OriginalFlag != "" {
+		// Boolean flag
+		description := fieldToken.Description
+		if description == "" {
+			description = fmt.Sprintf("Enable %s flag", fieldToken.OriginalFlag)
+		}
+		prop = &jsonschema.Schema{
+			Type:        "boolean",
+			Description: description,
+		}
+	} else if fieldToken.IsArray {
+		// Array field
+		description := fieldToken.Description
+		if description == "" {
+			description = "Additional command line arguments"
+		}
+		prop = &jsonschema.Schema{
+			Type: "array",
+			Items: &jsonschema.Schema{
+				Type:    fieldToken.ValueType(),
+				Enum:    enumValues(fieldToken.Choices, fieldToken.Type),
+				Pattern: fieldToken.Pattern,
+			},
+			Description: description,
+		}
+	} else {
+		// Scalar field (string unless a type was declared)
+		prop = &jsonschema.Schema{
+			Type:    fieldToken.ValueType(),
+			Enum:    enumValues(fieldToken.Choices, fieldToken.Type),
+			Pattern: fieldToken.Pattern,
+		}
+		if fieldToken.Description != "" {
+			prop.Description = fieldToken.Description
+		}
+	}
+
+	if value, ok := fieldToken.defaultValue(); ok {
+		if data, err := json.Marshal(value); err == nil {
+			prop.Default = data
+		}
+	}
+
+	return prop
+}
+
+// defaultValue converts the default written in a tag to a value of the field's type
+func (t FieldToken) defaultValue() (any, bool) {
+	if t.Default == "" {
+		return nil, false
+	}
+
+	if t.IsArray {
+		// Array defaults are whitespace-separated items
+		items := []any{}
+		for _, item := range strings.Fields(t.Default) {
+			items = append(items, parseLiteral(item, t.ValueType()))
+		}
+		return items, true
+	}
+
+	return parseLiteral(t.Default, t.ValueType()), true
+}
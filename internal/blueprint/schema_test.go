@@ -1,6 +1,7 @@
 package blueprint
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonschema"
@@ -261,6 +262,172 @@ func TestBlueprint_GenerateInputSchema(t *testing.T) {
 				Required: []string{"flag"},
 			},
 		},
+		{
+			name: "typed integer and number fields",
+			args: []string{"seq", "{{count: integer # how many}}", "[step: number]"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"count": {
+						Type:        "integer",
+						Description: "how many",
+					},
+					"step": {
+						Type: "number",
+					},
+				},
+				Required: []string{"count"},
+			},
+		},
+		{
+			name: "required boolean field",
+			args: []string{"tool", "--enabled={{enabled: boolean}}"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"enabled": {
+						Type: "boolean",
+					},
+				},
+				Required: []string{"enabled"},
+			},
+		},
+		{
+			name: "typed array items",
+			args: []string{"kill", "{{pids...: integer # process ids}}"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"pids": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "integer"},
+						Description: "process ids",
+					},
+				},
+				Required: []string{"pids"},
+			},
+		},
+		{
+			name: "enum choices",
+			args: []string{"say", "-v", "{{voice: alex|samantha|daniel # voice to use}}"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"voice": {
+						Type:        "string",
+						Enum:        []any{"alex", "samantha", "daniel"},
+						Description: "voice to use",
+					},
+				},
+				Required: []string{"voice"},
+			},
+		},
+		{
+			name: "typed enum choices",
+			args: []string{"nice", "-n", "[level: integer 0|10|19]"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"level": {
+						Type: "integer",
+						Enum: []any{float64(0), float64(10), float64(19)},
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			name: "array enum choices apply to items",
+			args: []string{"kubectl", "get", "[kinds...: pods|services]"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"kinds": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string", Enum: []any{"pods", "services"}},
+						Description: "Additional command line arguments",
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			name: "regex pattern",
+			args: []string{"jira", "[ticket: string /^[A-Z]+-\\d+$/ # ticket id]"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"ticket": {
+						Type:        "string",
+						Pattern:     `^[A-Z]+-\d+$`,
+						Description: "ticket id",
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			name: "default values",
+			args: []string{"tool", "[format = json # output format]", "[count: integer = 10]", "[paths... = src test]", "[--color = true]"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"format": {
+						Type:        "string",
+						Description: "output format",
+						Default:     json.RawMessage(`"json"`),
+					},
+					"count": {
+						Type:    "integer",
+						Default: json.RawMessage(`10`),
+					},
+					"paths": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "Additional command line arguments",
+						Default:     json.RawMessage(`["src","test"]`),
+					},
+					"color": {
+						Type:        "boolean",
+						Description: "Enable --color flag",
+						Default:     json.RawMessage(`true`),
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			name: "fields in optional groups are not required",
+			args: []string{"say", "[-v {{voice # the voice to use}}]", "{{text}}"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"voice": {
+						Type:        "string",
+						Description: "the voice to use",
+					},
+					"text": {
+						Type: "string",
+					},
+				},
+				Required: []string{"text"},
+			},
+		},
+		{
+			name: "repeated flag groups keep an array schema",
+			args: []string{"rg", "[--glob {{globs... # file globs}}]"},
+			expectedSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"globs": {
+						Type:        "array",
+						Items:       &jsonschema.Schema{Type: "string"},
+						Description: "file globs",
+					},
+				},
+				Required: []string{},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
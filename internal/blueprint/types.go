@@ -18,13 +18,37 @@ func (t TextToken) String() string {
 	return t.Value
 }
 
+// Field value types that can be declared in a template tag (e.g. {{count: integer}})
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
 // FieldToken represents a template field in a shell word
 type FieldToken struct {
 	Name         string
 	Description  string
 	Required     bool
-	IsArray      bool   // Indicates if this field represents an array (has ...)
-	OriginalFlag string // For boolean flags, stores the original flag format (e.g., "-f", "--verbose")
+	IsArray      bool     // Indicates if this field represents an array (has ...)
+	Separator    string   // For arrays, joins the items into a single word (e.g., "," for {{tags...,}})
+	OriginalFlag string   // For boolean flags, stores the original flag format (e.g., "-f", "--verbose")
+	Type         string   // Declared value type (e.g., "integer"); empty means the default for the field kind
+	Choices      []string // Allowed values declared as a|b|c; empty means any value
+	Pattern      string   // Regular expression declared as /regex/ that string values must match
+	Default      string   // Value used when an optional field is omitted, as written after = in the tag
+}
+
+// ValueType returns the JSON schema type of the field's value (or of each item for arrays)
+func (t FieldToken) ValueType() string {
+	if t.Type != "" {
+		return t.Type
+	}
+	if t.OriginalFlag != "" {
+		return TypeBoolean
+	}
+	return TypeString
 }
 
 func (t FieldToken) String() string {
@@ -34,6 +58,56 @@ func (t FieldToken) String() string {
 	return "[" + t.Name + "]"
 }
 
+// GroupToken represents an optional group of shell words such as [-v {{voice}}].
+// The whole group is rendered only when every required field inside it has a value.
+type GroupToken struct {
+	Words [][]Token
+}
+
+func (t GroupToken) String() string {
+	words := make([]string, len(t.Words))
+	for i, tokens := range t.Words {
+		var word strings.Builder
+		for _, token := range tokens {
+			word.WriteString(token.String())
+		}
+		words[i] = word.String()
+	}
+	return "[" + strings.Join(words, " ") + "]"
+}
+
+// walkFields calls fn for every field token in the given shell words, including
+// fields nested in optional groups
+func walkFields(words [][]Token, fn func(field FieldToken, inGroup bool)) {
+	for _, tokens := range words {
+		for _, token := range tokens {
+			switch t := token.(type) {
+			case FieldToken:
+				fn(t, false)
+			case GroupToken:
+				walkFields(t.Words, func(field FieldToken, _ bool) {
+					fn(field, true)
+				})
+			}
+		}
+	}
+}
+
+// mapFields replaces every field token in the given shell words, including fields
+// nested in optional groups
+func mapFields(words [][]Token, fn func(field FieldToken) FieldToken) {
+	for _, tokens := range words {
+		for i, token := range tokens {
+			switch t := token.(type) {
+			case FieldToken:
+				tokens[i] = fn(t)
+			case GroupToken:
+				mapFields(t.Words, fn)
+			}
+		}
+	}
+}
+
 // Blueprint represents a parsed command template
 type Blueprint struct {
 	BaseCommand string
@@ -45,6 +119,22 @@ func (bp *Blueprint) GetBaseCommand() string {
 	return bp.BaseCommand
 }
 
+// LiteralArgs returns the arguments after the base command, up to the first one with a field
+func (bp *Blueprint) LiteralArgs() []string {
+	var args []string
+	for _, tokens := range bp.ShellWords[1:] {
+		if len(tokens) != 1 {
+			break
+		}
+		text, ok := tokens[0].(TextToken)
+		if !ok {
+			break
+		}
+		args = append(args, text.Value)
+	}
+	return args
+}
+
 // GetCommandFormat returns the command format without the "Run the shell command" prefix
 func (bp *Blueprint) GetCommandFormat() string {
 	parts := make([]string, len(bp.ShellWords))
@@ -60,21 +150,37 @@ func (bp *Blueprint) renderTokensForDisplay(tokens []Token) string {
 		if fieldToken, ok := tokens[0].(FieldToken); ok {
 			return bp.renderFieldTokenForDisplay(fieldToken)
 		}
-		return tokens[0].String()
 	}
 
 	var result strings.Builder
-	for _, token := range tokens {
+	for i, token := range tokens {
 		switch t := token.(type) {
 		case TextToken:
-			result.WriteString(t.Value)
+			// Escape literal brackets so the format parses back to the same blueprint
+			followedByTag := false
+			if i+1 < len(tokens) {
+				_, nextIsText := tokens[i+1].(TextToken)
+				followedByTag = !nextIsText
+			}
+			result.WriteString(escapeText(t.Value, followedByTag))
 		case FieldToken:
 			result.WriteString(bp.renderFieldTokenForDisplay(t))
+		case GroupToken:
+			result.WriteString(bp.renderGroupTokenForDisplay(t))
 		}
 	}
 	return result.String()
 }
 
+// renderGroupTokenForDisplay renders an optional group for display, e.g. [-v {{voice}}]
+func (bp *Blueprint) renderGroupTokenForDisplay(token GroupToken) string {
+	words := make([]string, len(token.Words))
+	for i, tokens := range token.Words {
+		words[i] = bp.renderTokensForDisplay(tokens)
+	}
+	return "[" + strings.Join(words, " ") + "]"
+}
+
 // renderFieldTokenForDisplay renders a single field token for display
 func (bp *Blueprint) renderFieldTokenForDisplay(token FieldToken) string {
 	name := token.Name
@@ -85,7 +191,7 @@ func (bp *Blueprint) renderFieldTokenForDisplay(token FieldToken) string {
 	}
 
 	if token.IsArray {
-		name = name + "..."
+		name = name + "..." + displaySeparator(token.Separator)
 	}
 
 	// For required fields, use template format
@@ -101,3 +207,31 @@ func (bp *Blueprint) renderFieldTokenForDisplay(token FieldToken) string {
 func (bp *Blueprint) GetInputSchema() interface{} {
 	return bp.GenerateInputSchema()
 }
+
+// displaySeparator renders an array separator the way it is written in a tag
+func displaySeparator(separator string) string {
+	if strings.ContainsAny(separator, " \t:=#/\"") {
+		return `"` + separator + `"`
+	}
+	return separator
+}
+
+// escapeText escapes characters in literal text that would otherwise be parsed as
+// template syntax. followedByTag indicates the text is immediately followed by a tag.
+func escapeText(text string, followedByTag bool) string {
+	var result strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		atEnd := i+1 == len(text)
+		switch {
+		case c == '[' || c == ']':
+			result.WriteByte('\\')
+		case c == '{' && ((!atEnd && text[i+1] == '{') || (atEnd && followedByTag)):
+			result.WriteByte('\\')
+		case c == '\\' && (isEscape(text, i) || (followedByTag && strings.Trim(text[i:], `\\`) == "")):
+			result.WriteByte('\\')
+		}
+		result.WriteByte(c)
+	}
+	return result.String()
+}
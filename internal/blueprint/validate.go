@@ -0,0 +1,196 @@
+package blueprint
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// validateParam checks a parameter value against its property schema
+func validateParam(name string, value interface{}, schema *jsonschema.Schema) error {
+	switch schema.Type {
+	case "array":
+		items, ok := toSlice(value)
+		if !ok {
+			return fmt.Errorf("parameter '%s' must be an array, got %T", name, value)
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				if err := validateParam(fmt.Sprintf("%s[%d]", name, i), item, schema.Items); err != nil {
+					return err
+				}
+			}
+		}
+	case TypeInteger:
+		if !isInteger(value) {
+			return fmt.Errorf("parameter '%s' must be an integer, got %s", name, describeValue(value))
+		}
+	case TypeNumber:
+		if !isNumber(value) {
+			return fmt.Errorf("parameter '%s' must be a number, got %s", name, describeValue(value))
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("parameter '%s' must be a boolean, got %s", name, describeValue(value))
+		}
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		return fmt.Errorf("parameter '%s' must be one of %s, got %s", name, formatEnum(schema.Enum), describeValue(value))
+	}
+
+	// Like JSON schema, patterns only constrain strings and are not anchored
+	if str, ok := value.(string); ok && schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("parameter '%s' has an invalid pattern: %w", name, err)
+		}
+		if !re.MatchString(str) {
+			return fmt.Errorf("parameter '%s' must match /%s/, got %s", name, schema.Pattern, describeValue(value))
+		}
+	}
+	return nil
+}
+
+// enumContains reports whether value matches one of the enum values
+func enumContains(enum []any, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+		// Numbers may arrive as any numeric type
+		if a, ok := toFloat(allowed); ok {
+			if v, ok := toFloat(value); ok && a == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatEnum formats enum values for error messages
+func formatEnum(enum []any) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		values[i] = fmt.Sprintf("%v", v)
+	}
+	return strings.Join(values, ", ")
+}
+
+// enumValues converts declared choices to schema enum values of the given type
+func enumValues(choices []string, fieldType string) []any {
+	if len(choices) == 0 {
+		return nil
+	}
+	values := make([]any, len(choices))
+	for i, choice := range choices {
+		values[i] = parseLiteral(choice, fieldType)
+	}
+	return values
+}
+
+// isValidLiteral reports whether text can be parsed as a value of the given type
+func isValidLiteral(text, fieldType string) bool {
+	switch fieldType {
+	case TypeInteger:
+		_, err := strconv.ParseInt(text, 10, 64)
+		return err == nil
+	case TypeNumber:
+		_, err := strconv.ParseFloat(text, 64)
+		return err == nil
+	case TypeBoolean:
+		_, err := strconv.ParseBool(text)
+		return err == nil
+	}
+	return true
+}
+
+// parseLiteral converts text written in a template tag to a value of the given type
+func parseLiteral(text, fieldType string) any {
+	switch fieldType {
+	case TypeInteger, TypeNumber:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	}
+	return text
+}
+
+// toFloat converts a numeric value to float64
+func toFloat(value interface{}) (float64, bool) {
+	if !isNumber(value) {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	f, err := strconv.ParseFloat(fmt.Sprintf("%v", value), 64)
+	return f, err == nil
+}
+
+// toSlice returns the items of an array parameter value
+func toSlice(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// isNumber reports whether a value is numeric (JSON numbers decode as float64)
+func isNumber(value interface{}) bool {
+	switch v := value.(type) {
+	case float64:
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	case float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case json.Number:
+		_, err := v.Float64()
+		return err == nil
+	}
+	return false
+}
+
+// isInteger reports whether a value is a whole number
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case float64:
+		return isNumber(v) && v == math.Trunc(v)
+	case float32:
+		return v == float32(math.Trunc(float64(v)))
+	case json.Number:
+		_, err := v.Int64()
+		return err == nil
+	}
+	return isNumber(value)
+}
+
+// describeValue describes a value for validation error messages
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
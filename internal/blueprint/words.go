@@ -0,0 +1,75 @@
+package blueprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitWords splits a line of blueprint text into shell words, for blueprints written
+// on one line like a script header. Whitespace separates words except inside quotes,
+// {{fields}} and [optional fields], so [-v {{voice # the voice}}] is a single word.
+// Quotes are removed; backslashes are kept for the blueprint to read.
+func SplitWords(line string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote byte
+	tagDepth, optionalDepth := 0, 0
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		if quote != 0 {
+			switch {
+			case c == quote:
+				quote = 0
+			case c == '\\' && quote == '"' && i+1 < len(line) && line[i+1] == '"':
+				i++
+				current.WriteByte('"')
+			default:
+				current.WriteByte(c)
+			}
+			continue
+		}
+
+		switch {
+		case isEscape(line, i):
+			current.WriteString(line[i : i+2])
+			i++
+		case (c == '"' || c == '\'') && tagDepth == 0 && optionalDepth == 0:
+			quote = c
+		case strings.HasPrefix(line[i:], "{{"):
+			tagDepth++
+			current.WriteString("{{")
+			i++
+		case strings.HasPrefix(line[i:], "}}") && tagDepth > 0:
+			tagDepth--
+			current.WriteString("}}")
+			i++
+		case c == '[':
+			optionalDepth++
+			current.WriteByte(c)
+		case c == ']' && optionalDepth > 0:
+			optionalDepth--
+			current.WriteByte(c)
+		case (c == ' ' || c == '\t') && tagDepth == 0 && optionalDepth == 0:
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+			continue
+		default:
+			current.WriteByte(c)
+		}
+		inWord = true
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed %c quote in %q", quote, line)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
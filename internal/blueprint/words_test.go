@@ -0,0 +1,75 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlueprint_SplitWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{
+			name:     "plain words",
+			line:     "  git   log\t--oneline ",
+			expected: []string{"git", "log", "--oneline"},
+		},
+		{
+			name:     "fields with spaces",
+			line:     "{{env # target env}} [--dry-run # only print] [paths... # files to deploy]",
+			expected: []string{"{{env # target env}}", "[--dry-run # only print]", "[paths... # files to deploy]"},
+		},
+		{
+			name:     "groups with nested fields",
+			line:     "say [-v {{voice # the voice}}] {{text}}",
+			expected: []string{"say", "[-v {{voice # the voice}}]", "{{text}}"},
+		},
+		{
+			name:     "fields inside a word",
+			line:     "--env={{env # target env}}.example.com",
+			expected: []string{"--env={{env # target env}}.example.com"},
+		},
+		{
+			name:     "quotes group words and are removed",
+			line:     `echo "hello world" 'it''s' "say \"hi\""`,
+			expected: []string{"echo", "hello world", "its", `say "hi"`},
+		},
+		{
+			name:     "quotes inside fields are kept",
+			line:     `{{tags..." " # it's a list}}`,
+			expected: []string{`{{tags..." " # it's a list}}`},
+		},
+		{
+			name:     "escaped brackets don't group words",
+			line:     `jq .items\[ 0 \]`,
+			expected: []string{`jq`, `.items\[`, `0`, `\]`},
+		},
+		{
+			name:     "empty line",
+			line:     "   ",
+			expected: nil,
+		},
+		{
+			name:     "empty quotes are a word",
+			line:     `printf ""`,
+			expected: []string{"printf", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := SplitWords(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, words)
+		})
+	}
+
+	t.Run("reports unclosed quotes", func(t *testing.T) {
+		_, err := SplitWords(`echo "hello`)
+		assert.ErrorContains(t, err, `unclosed " quote`)
+	})
+}
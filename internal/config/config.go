@@ -0,0 +1,198 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the contents of a studio-mcp config file
+type Config struct {
+	Tools []Tool `yaml:"tools" json:"tools"`
+}
+
+// Tool declares a single tool in a config file
+type Tool struct {
+	Name        string            `yaml:"name" json:"name"`               // Tool name; defaults to the base command
+	Title       string            `yaml:"title" json:"title"`             // Human readable name shown by clients
+	Description string            `yaml:"description" json:"description"` // Tool description; defaults to the command format
+	Command     []string          `yaml:"command" json:"command"`         // Blueprint, one shell word per item
+	Dir         string            `yaml:"dir" json:"dir"`                 // Working directory, relative to the config file
+	Env         map[string]string `yaml:"env" json:"env"`                 // Extra environment variables
+	Timeout     Duration          `yaml:"timeout" json:"timeout"`         // Stops the command after this long
+
+	Annotations      Annotations `yaml:"annotations" json:"annotations"`           // Hints for clients about what the tool does
+	InferAnnotations bool        `yaml:"inferAnnotations" json:"inferAnnotations"` // Guess undeclared hints from the command
+
+	// Subcommands fan the tool out into one tool per subcommand, each running the
+	// command followed by the subcommand's words. The command's own fields are
+	// global flags shared by every subcommand.
+	Subcommands []Tool `yaml:"subcommands" json:"subcommands"`
+	// Dispatch serves the subcommands as a single tool instead, with an action field
+	// that chooses which one runs
+	Dispatch bool `yaml:"dispatch" json:"dispatch"`
+}
+
+// Annotations declares MCP tool hints using their names from the MCP specification
+type Annotations struct {
+	ReadOnly    *bool `yaml:"readOnlyHint" json:"readOnlyHint"`
+	Destructive *bool `yaml:"destructiveHint" json:"destructiveHint"`
+	Idempotent  *bool `yaml:"idempotentHint" json:"idempotentHint"`
+	OpenWorld   *bool `yaml:"openWorldHint" json:"openWorldHint"`
+}
+
+// Load reads a YAML or JSON config file. Files ending in .json are read as JSON,
+// anything else as YAML.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+
+	cfg, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Relative working directories are relative to the config file
+	for i := range cfg.Tools {
+		cfg.Tools[i].resolveDir(filepath.Dir(path))
+	}
+
+	return cfg, nil
+}
+
+// resolveDir makes the working directories of a tool and its subcommands relative to base
+func (t *Tool) resolveDir(base string) {
+	if t.Dir != "" && !filepath.IsAbs(t.Dir) {
+		t.Dir = filepath.Join(base, t.Dir)
+	}
+	for i := range t.Subcommands {
+		t.Subcommands[i].resolveDir(base)
+	}
+}
+
+// Parse decodes and validates config data in the given format ("yaml" or "json")
+func Parse(data []byte, format string) (*Config, error) {
+	var cfg Config
+
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("invalid JSON config: %w", err)
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid YAML config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validate checks that every tool can be built
+func (c *Config) validate() error {
+	if len(c.Tools) == 0 {
+		return fmt.Errorf("config declares no tools")
+	}
+
+	for i, tool := range c.Tools {
+		label := "tool " + tool.label(i)
+		if err := tool.validate(label); err != nil {
+			return err
+		}
+
+		if tool.Dispatch && len(tool.Subcommands) == 0 {
+			return fmt.Errorf("%s dispatches but has no subcommands to dispatch to", label)
+		}
+
+		for j, sub := range tool.Subcommands {
+			subLabel := label + " subcommand " + sub.label(j)
+			if err := sub.validate(subLabel); err != nil {
+				return err
+			}
+			if len(sub.Subcommands) > 0 || sub.Dispatch {
+				return fmt.Errorf("%s can't have subcommands", subLabel)
+			}
+			// Dispatch actions share the tool's options
+			if tool.Dispatch && !sub.isAction() {
+				return fmt.Errorf("%s is a dispatch action, which can only declare a name, description and command", subLabel)
+			}
+		}
+	}
+	return nil
+}
+
+// isAction reports whether a subcommand only declares what a dispatch action can have
+func (t Tool) isAction() bool {
+	return t.Title == "" && t.Dir == "" && len(t.Env) == 0 && t.Timeout == 0 &&
+		t.Annotations == (Annotations{}) && !t.InferAnnotations
+}
+
+// validate checks that a tool can be built, naming it by label in errors
+func (t Tool) validate(label string) error {
+	if len(t.Command) == 0 || strings.TrimSpace(t.Command[0]) == "" {
+		return fmt.Errorf("%s has no command", label)
+	}
+	for key := range t.Env {
+		if key == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("%s has an invalid environment variable name %q", label, key)
+		}
+	}
+	return nil
+}
+
+// label identifies a tool in error messages by its name or its position
+func (t Tool) label(index int) string {
+	if t.Name != "" {
+		return fmt.Sprintf("%q", t.Name)
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
+
+// invalidToolNameChars matches the characters replaced in tool names made from
+// Makefile targets and npm scripts
+var invalidToolNameChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// prefixedToolName names a tool after a task from a task runner, like make_build for
+// the build target of a Makefile
+func prefixedToolName(prefix, task string) string {
+	name := prefix + "_" + strings.Trim(invalidToolNameChars.ReplaceAllString(task, "_"), "_")
+	if len(name) > 64 {
+		name = strings.TrimRight(name[:64], "_")
+	}
+	return name
+}
+
+// Environ returns the tool's environment variables as sorted KEY=value pairs
+func (t Tool) Environ() []string {
+	env := make([]string, 0, len(t.Env))
+	for key, value := range t.Env {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	t.Run("parses YAML", func(t *testing.T) {
+		cfg, err := Parse([]byte(`
+tools:
+  - name: speak
+    description: Say something out loud
+    command: [say, "{{text # what to say}}"]
+    env:
+      LANG: en_US.UTF-8
+    annotations:
+      readOnlyHint: false
+      openWorldHint: false
+  - inferAnnotations: true
+    command:
+      - date
+      - "[+format]"
+`), "yaml")
+		require.NoError(t, err)
+		require.Len(t, cfg.Tools, 2)
+
+		assert.Equal(t, "speak", cfg.Tools[0].Name)
+		assert.Equal(t, "Say something out loud", cfg.Tools[0].Description)
+		assert.Equal(t, []string{"say", "{{text # what to say}}"}, cfg.Tools[0].Command)
+		assert.Equal(t, []string{"LANG=en_US.UTF-8"}, cfg.Tools[0].Environ())
+		assert.Equal(t, []string{"date", "[+format]"}, cfg.Tools[1].Command)
+
+		no := false
+		assert.Equal(t, Annotations{ReadOnly: &no, OpenWorld: &no}, cfg.Tools[0].Annotations)
+		assert.False(t, cfg.Tools[0].InferAnnotations)
+		assert.Equal(t, Annotations{}, cfg.Tools[1].Annotations)
+		assert.True(t, cfg.Tools[1].InferAnnotations)
+	})
+
+	t.Run("parses JSON", func(t *testing.T) {
+		cfg, err := Parse([]byte(`{"tools": [{"name": "echo", "command": ["echo", "{{text}}"], "dir": "/tmp"}]}`), "json")
+		require.NoError(t, err)
+		require.Len(t, cfg.Tools, 1)
+		assert.Equal(t, "echo", cfg.Tools[0].Name)
+		assert.Equal(t, "/tmp", cfg.Tools[0].Dir)
+	})
+
+	errors := []struct {
+		name   string
+		data   string
+		format string
+		errMsg string
+	}{
+		{
+			name:   "empty file",
+			data:   "",
+			format: "yaml",
+			errMsg: "config declares no tools",
+		},
+		{
+			name:   "unknown YAML key",
+			data:   "tools:\n  - comand: [echo]\n",
+			format: "yaml",
+			errMsg: "field comand not found",
+		},
+		{
+			name:   "unknown JSON key",
+			data:   `{"tools": [{"command": ["echo"], "cwd": "/"}]}`,
+			format: "json",
+			errMsg: `unknown field "cwd"`,
+		},
+		{
+			name:   "missing command",
+			data:   "tools:\n  - name: nothing\n",
+			format: "yaml",
+			errMsg: `tool "nothing" has no command`,
+		},
+		{
+			name:   "empty command",
+			data:   "tools:\n  - command: [echo]\n  - command: [' ']\n",
+			format: "yaml",
+			errMsg: "tool #2 has no command",
+		},
+		{
+			name:   "invalid environment variable",
+			data:   "tools:\n  - command: [env]\n    env: {'A=B': c}\n",
+			format: "yaml",
+			errMsg: `invalid environment variable name "A=B"`,
+		},
+		{
+			name:   "subcommand without a command",
+			data:   "tools:\n  - command: [git]\n    subcommands:\n      - name: status\n",
+			format: "yaml",
+			errMsg: `tool #1 subcommand "status" has no command`,
+		},
+		{
+			name:   "nested subcommands",
+			data:   "tools:\n  - command: [gh]\n    subcommands:\n      - command: [pr]\n        subcommands: [{command: [list]}]\n",
+			format: "yaml",
+			errMsg: "tool #1 subcommand #1 can't have subcommands",
+		},
+		{
+			name:   "dispatch without subcommands",
+			data:   "tools:\n  - command: [git]\n    dispatch: true\n",
+			format: "yaml",
+			errMsg: "tool #1 dispatches but has no subcommands to dispatch to",
+		},
+		{
+			name:   "dispatch action with its own options",
+			data:   "tools:\n  - name: git\n    command: [git]\n    dispatch: true\n    subcommands:\n      - command: [status]\n        dir: src\n",
+			format: "yaml",
+			errMsg: `tool "git" subcommand #1 is a dispatch action, which can only declare a name, description and command`,
+		},
+		{
+			name:   "unsupported format",
+			data:   "",
+			format: "toml",
+			errMsg: "unsupported config format: toml",
+		},
+	}
+
+	for _, tt := range errors {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), tt.format)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestConfig_Load(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("picks the format from the extension", func(t *testing.T) {
+		path := filepath.Join(dir, "tools.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"tools": [{"command": ["ls"]}]}`), 0644))
+
+		cfg, err := Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ls"}, cfg.Tools[0].Command)
+	})
+
+	t.Run("resolves working directories relative to the file", func(t *testing.T) {
+		path := filepath.Join(dir, "tools.yml")
+		require.NoError(t, os.WriteFile(path, []byte("tools:\n  - command: [ls]\n    dir: src\n  - command: [pwd]\n    dir: /usr\n    subcommands: [{command: [-P], dir: bin}]\n"), 0644))
+
+		cfg, err := Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "src"), cfg.Tools[0].Dir)
+		assert.Equal(t, "/usr", cfg.Tools[1].Dir)
+		assert.Equal(t, filepath.Join(dir, "bin"), cfg.Tools[1].Subcommands[0].Dir)
+	})
+
+	t.Run("names the file in errors", func(t *testing.T) {
+		path := filepath.Join(dir, "broken.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("tools: nope\n"), 0644))
+
+		_, err := Load(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), path)
+	})
+
+	t.Run("reports missing files", func(t *testing.T) {
+		_, err := Load(filepath.Join(dir, "missing.yaml"))
+		assert.ErrorContains(t, err, "failed to read config")
+	})
+}
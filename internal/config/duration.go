@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a length of time in a config file, written like 30s or 2m, or as a
+// number of seconds
+type Duration time.Duration
+
+// ParseDuration parses a positive duration like 30s, 2m or 1h30m, or a number of
+// seconds like 30 or 0.5
+func ParseDuration(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		seconds, numberErr := strconv.ParseFloat(text, 64)
+		if numberErr != nil {
+			return 0, fmt.Errorf("invalid duration %q: use a number of seconds or a duration like 30s or 2m", text)
+		}
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: must be more than zero", text)
+	}
+	return duration, nil
+}
+
+// UnmarshalYAML reads a duration from a YAML string or number
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: durations must be a string like 30s or a number of seconds", node.Line)
+	}
+
+	duration, err := ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// UnmarshalJSON reads a duration from a JSON string or number
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("durations must be a string like 30s or a number of seconds")
+	}
+
+	duration, err := ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ParseDuration(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected time.Duration
+		errMsg   string
+	}{
+		{text: "30s", expected: 30 * time.Second},
+		{text: "1h30m", expected: 90 * time.Minute},
+		{text: "45", expected: 45 * time.Second},
+		{text: " 0.5 ", expected: 500 * time.Millisecond},
+		{text: "soon", errMsg: `invalid duration "soon": use a number of seconds or a duration like 30s or 2m`},
+		{text: "0", errMsg: `invalid duration "0": must be more than zero`},
+		{text: "-5s", errMsg: `invalid duration "-5s": must be more than zero`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			duration, err := ParseDuration(tt.text)
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, duration)
+		})
+	}
+}
+
+func TestConfig_Timeout(t *testing.T) {
+	t.Run("reads YAML strings and numbers", func(t *testing.T) {
+		cfg, err := Parse([]byte("tools:\n  - command: [curl]\n    timeout: 2m\n  - command: [make]\n    timeout: 90\n"), "yaml")
+		require.NoError(t, err)
+		assert.Equal(t, Duration(2*time.Minute), cfg.Tools[0].Timeout)
+		assert.Equal(t, Duration(90*time.Second), cfg.Tools[1].Timeout)
+	})
+
+	t.Run("reads JSON strings and numbers", func(t *testing.T) {
+		cfg, err := Parse([]byte(`{"tools": [{"command": ["curl"], "timeout": "10s"}, {"command": ["make"], "timeout": 1.5}]}`), "json")
+		require.NoError(t, err)
+		assert.Equal(t, Duration(10*time.Second), cfg.Tools[0].Timeout)
+		assert.Equal(t, Duration(1500*time.Millisecond), cfg.Tools[1].Timeout)
+	})
+
+	t.Run("rejects invalid timeouts", func(t *testing.T) {
+		_, err := Parse([]byte("tools:\n  - command: [curl]\n    timeout: forever\n"), "yaml")
+		assert.ErrorContains(t, err, `line 3: invalid duration "forever"`)
+
+		_, err = Parse([]byte(`{"tools": [{"command": ["curl"], "timeout": true}]}`), "json")
+		assert.ErrorContains(t, err, "durations must be a string like 30s or a number of seconds")
+	})
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// makeVarTag matches the {{VAR}} and [VAR] tags in a target's comment that declare
+// variable overrides. Only uppercase names count, so other brackets stay in the description.
+var makeVarTag = regexp.MustCompile(`\{\{\s*([A-Z_][A-Z0-9_]*)\b[^{}]*\}\}|\[\s*([A-Z_][A-Z0-9_]*)\b[^\[\]]*\]`)
+
+// defaultMakefiles are the names make looks for without -f
+var defaultMakefiles = map[string]bool{"GNUmakefile": true, "makefile": true, "Makefile": true}
+
+// makeTarget is a target found in a Makefile with its ## comment
+type makeTarget struct {
+	Name    string
+	Comment string
+}
+
+// LoadMakefile declares a tool for every target in a Makefile with a ## comment, either
+// at the end of the target line or on the lines just above it:
+//
+//	build: ## Build the binary [VERSION # version to embed]
+//
+// If no target has one, the .PHONY targets are served instead. Uppercase {{VAR}} and
+// [VAR] tags in the comment become VAR=value overrides on the make command line.
+func LoadMakefile(path string) ([]Tool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Makefile: %w", err)
+	}
+	defer file.Close()
+
+	targets, err := parseMakefile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Makefile: %w", err)
+	}
+
+	command := []string{"make"}
+	if !defaultMakefiles[filepath.Base(absPath)] {
+		command = append(command, "-f", filepath.Base(absPath))
+	}
+
+	tools := make([]Tool, 0, len(targets))
+	for _, target := range targets {
+		tools = append(tools, targetTool(target, command, filepath.Dir(absPath)))
+	}
+	return tools, nil
+}
+
+// targetTool declares the tool that runs a make target from dir
+func targetTool(target makeTarget, command []string, dir string) Tool {
+	args := append(append([]string{}, command...), target.Name)
+	for _, match := range makeVarTag.FindAllStringSubmatch(target.Comment, -1) {
+		tag, name := match[0], match[1]
+		if strings.HasPrefix(tag, "[") {
+			name = match[2]
+		}
+		args = append(args, makeVarWord(tag, name))
+	}
+
+	description := makeVarTag.ReplaceAllString(target.Comment, "")
+	description = strings.Join(strings.Fields(description), " ")
+
+	return Tool{
+		Name:        prefixedToolName("make", target.Name),
+		Description: description,
+		Command:     args,
+		Dir:         dir,
+	}
+}
+
+// makeVarWord converts a variable tag into the blueprint word that passes it to make.
+// Required tags always pass VAR=value. Optional tags are left out when they have no
+// value, unless they declare a default.
+func makeVarWord(tag, name string) string {
+	if strings.HasPrefix(tag, "{{") {
+		return name + "=" + tag
+	}
+
+	content := tag[1 : len(tag)-1]
+	spec, _, _ := strings.Cut(content, "#")
+	if strings.Contains(spec, "=") {
+		return name + "=" + tag
+	}
+	return "[" + name + "={{" + content + "}}]"
+}
+
+// parseMakefile finds the targets to serve from a Makefile
+func parseMakefile(r io.Reader) ([]makeTarget, error) {
+	var targets, phonyTargets []makeTarget
+	seen := map[string]bool{}
+	var pending []string // ## comment lines waiting for the target below them
+
+	lines, err := makefileLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		// Recipes and everything else indented by a tab belong to the target above
+		if strings.HasPrefix(line, "\t") {
+			pending = nil
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "##") {
+			pending = append(pending, strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+			continue
+		}
+
+		names, comment, ok := parseTargetLine(trimmed)
+		if !ok {
+			pending = nil
+			continue
+		}
+		if comment == "" {
+			comment = strings.Join(pending, " ")
+		}
+		pending = nil
+
+		if len(names) == 1 && names[0] == ".PHONY" {
+			_, prerequisites, _ := strings.Cut(trimmed, ":")
+			for _, name := range strings.Fields(prerequisites) {
+				phonyTargets = append(phonyTargets, makeTarget{Name: name})
+			}
+			continue
+		}
+
+		for _, name := range names {
+			if seen[name] || !servableTarget(name) {
+				continue
+			}
+			if comment != "" {
+				seen[name] = true
+				targets = append(targets, makeTarget{Name: name, Comment: comment})
+			}
+		}
+	}
+
+	if len(targets) > 0 {
+		return targets, nil
+	}
+
+	// Without any ## comments, the .PHONY targets are the Makefile's tasks
+	for _, target := range phonyTargets {
+		if !seen[target.Name] && servableTarget(target.Name) {
+			seen[target.Name] = true
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// makefileLines reads a Makefile's lines, joining lines continued with a backslash
+func makefileLines(r io.Reader) ([]string, error) {
+	var lines []string
+	var continued strings.Builder
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, `\`) {
+			continued.WriteString(strings.TrimSuffix(line, `\`) + " ")
+			continue
+		}
+		continued.WriteString(line)
+		lines = append(lines, continued.String())
+		continued.Reset()
+	}
+	if continued.Len() > 0 {
+		lines = append(lines, continued.String())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Makefile: %w", err)
+	}
+	return lines, nil
+}
+
+// parseTargetLine splits a rule like "build test: deps ## comment" into its target
+// names and ## comment. Variable assignments and other lines are not rules.
+func parseTargetLine(line string) ([]string, string, bool) {
+	rule, comment, _ := strings.Cut(line, "##")
+	if strings.HasPrefix(rule, "#") {
+		return nil, "", false
+	}
+
+	colon := strings.Index(rule, ":")
+	if colon <= 0 {
+		return nil, "", false
+	}
+	// VAR := value, VAR ::= value and VAR = a:b are assignments
+	if strings.ContainsAny(rule[:colon], "=$") || strings.HasPrefix(rule[colon:], ":=") || strings.HasPrefix(rule[colon:], "::=") {
+		return nil, "", false
+	}
+
+	names := strings.Fields(rule[:colon])
+	if len(names) == 0 {
+		return nil, "", false
+	}
+	return names, strings.TrimSpace(comment), true
+}
+
+// servableTarget reports whether a target can be run on its own. Special targets like
+// .DEFAULT and pattern rules like %.o can't, and names with brackets or braces would
+// read as blueprint fields.
+func servableTarget(name string) bool {
+	return !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, "%$[]{}")
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ParseMakefile(t *testing.T) {
+	tests := []struct {
+		name     string
+		makefile string
+		expected []makeTarget
+	}{
+		{
+			name: "targets with ## comments",
+			makefile: `.PHONY: build test clean
+
+# Default target
+build: deps ## Build the binary
+	go build ./...
+
+VERSION ?= $(shell git describe)
+DATE := $(shell date +%Y:%m)
+
+## Run the tests
+## with the race detector
+test:
+	go test -race ./...
+
+clean:
+	rm -rf bin
+`,
+			expected: []makeTarget{
+				{Name: "build", Comment: "Build the binary"},
+				{Name: "test", Comment: "Run the tests with the race detector"},
+			},
+		},
+		{
+			name: "phony targets without comments",
+			makefile: `.PHONY: build \
+	test
+.PHONY: lint
+build:
+	go build ./...
+bin/app: main.go
+	go build -o $@
+`,
+			expected: []makeTarget{{Name: "build"}, {Name: "test"}, {Name: "lint"}},
+		},
+		{
+			name: "skips special targets, pattern rules and duplicates",
+			makefile: `.DEFAULT_GOAL := build
+%.o: %.c ## Compile C
+	cc -c $<
+$(BIN): ## Build the binary
+	go build
+build lint: ## Build or lint
+build: ## Built again
+export PATH := bin:$(PATH)
+`,
+			expected: []makeTarget{
+				{Name: "build", Comment: "Build or lint"},
+				{Name: "lint", Comment: "Build or lint"},
+			},
+		},
+		{
+			name:     "comments above a recipe line belong to nothing",
+			makefile: "## Orphaned\n\t@echo hi\nbuild:\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := parseMakefile(strings.NewReader(tt.makefile))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, targets)
+		})
+	}
+}
+
+func TestConfig_LoadMakefile(t *testing.T) {
+	dir := t.TempDir()
+	absDir, err := filepath.Abs(dir)
+	require.NoError(t, err)
+
+	makefile := `build: ## Build the binary [VERSION # version to embed] [GOOS = linux]
+	go build
+release: ## Release {{VERSION # the new version}} to [REMOTE: origin|upstream]
+	git push
+sync-version: ## Sync package.json [with the latest tag]
+	./sync.sh
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Makefile"), []byte(makefile), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "build.mk"), []byte(makefile), 0644))
+
+	tools, err := LoadMakefile(filepath.Join(dir, "Makefile"))
+	require.NoError(t, err)
+	assert.Equal(t, []Tool{
+		{
+			Name:        "make_build",
+			Description: "Build the binary",
+			Command:     []string{"make", "build", "[VERSION={{VERSION # version to embed}}]", "GOOS=[GOOS = linux]"},
+			Dir:         absDir,
+		},
+		{
+			Name:        "make_release",
+			Description: "Release to",
+			Command:     []string{"make", "release", "VERSION={{VERSION # the new version}}", "[REMOTE={{REMOTE: origin|upstream}}]"},
+			Dir:         absDir,
+		},
+		{
+			Name:        "make_sync_version",
+			Description: "Sync package.json [with the latest tag]",
+			Command:     []string{"make", "sync-version"},
+			Dir:         absDir,
+		},
+	}, tools)
+
+	t.Run("passes other Makefiles with -f", func(t *testing.T) {
+		tools, err := LoadMakefile(filepath.Join(dir, "build.mk"))
+		require.NoError(t, err)
+		require.NotEmpty(t, tools)
+		assert.Equal(t, []string{"make", "-f", "build.mk", "sync-version"}, tools[2].Command)
+	})
+
+	t.Run("reports a missing Makefile", func(t *testing.T) {
+		_, err := LoadMakefile(filepath.Join(dir, "missing"))
+		assert.ErrorContains(t, err, "failed to read Makefile")
+	})
+}
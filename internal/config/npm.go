@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// npmScriptArgs is the blueprint field that passes extra arguments to an npm script
+const npmScriptArgs = "[args... # extra arguments for the script]"
+
+// npmLifecycleScripts run on their own during npm install, publish and version rather
+// than with npm run
+var npmLifecycleScripts = map[string]bool{
+	"preinstall": true, "install": true, "postinstall": true,
+	"preuninstall": true, "uninstall": true, "postuninstall": true,
+	"prepublish": true, "prepublishOnly": true, "publish": true, "postpublish": true,
+	"preprepare": true, "prepare": true, "postprepare": true,
+	"prepack": true, "postpack": true, "dependencies": true,
+	"preversion": true, "version": true, "postversion": true,
+}
+
+// packageJSON is the part of a package.json that declares tools
+type packageJSON struct {
+	Scripts map[string]string `json:"scripts"`
+	Studio  map[string]string `json:"studio"` // Tool descriptions by script name
+}
+
+// LoadPackageJSON declares a tool for every script in a package.json that runs
+// npm run <name> -- [args...]. Descriptions come from the optional studio key:
+//
+//	"studio": {"test": "Run the unit tests"}
+//
+// Lifecycle scripts like postinstall, and the pre and post hooks of other scripts,
+// are skipped since npm runs them on its own.
+func LoadPackageJSON(path string) ([]Tool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("%s: invalid package.json: %w", path, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		// Brackets and braces in a name would read as blueprint fields
+		if !pkg.isHook(name) && !strings.ContainsAny(name, "[]{}") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	tools := make([]Tool, 0, len(names))
+	for _, name := range names {
+		description := pkg.Studio[name]
+		if description == "" {
+			description = fmt.Sprintf("Run the npm script %q: %s", name, pkg.Scripts[name])
+		}
+
+		tools = append(tools, Tool{
+			Name:        prefixedToolName("npm", name),
+			Description: description,
+			Command:     []string{"npm", "run", name, "--", npmScriptArgs},
+			Dir:         filepath.Dir(absPath),
+		})
+	}
+	return tools, nil
+}
+
+// isHook reports whether npm runs a script on its own: lifecycle scripts, and
+// pre<name> and post<name> around another script
+func (p packageJSON) isHook(name string) bool {
+	if npmLifecycleScripts[name] {
+		return true
+	}
+	for _, prefix := range []string{"pre", "post"} {
+		if task, ok := strings.CutPrefix(name, prefix); ok {
+			if _, exists := p.Scripts[task]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_LoadPackageJSON(t *testing.T) {
+	dir := t.TempDir()
+	absDir, err := filepath.Abs(dir)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "package.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "name": "app",
+  "scripts": {
+    "test": "jest",
+    "pretest": "eslint .",
+    "build:prod": "vite build",
+    "postinstall": "node setup.js",
+    "preview": "vite preview"
+  },
+  "studio": {
+    "test": "Run the unit tests with jest"
+  }
+}`), 0644))
+
+	tools, err := LoadPackageJSON(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Tool{
+		{
+			Name:        "npm_build_prod",
+			Description: `Run the npm script "build:prod": vite build`,
+			Command:     []string{"npm", "run", "build:prod", "--", npmScriptArgs},
+			Dir:         absDir,
+		},
+		{
+			Name:        "npm_preview",
+			Description: `Run the npm script "preview": vite preview`,
+			Command:     []string{"npm", "run", "preview", "--", npmScriptArgs},
+			Dir:         absDir,
+		},
+		{
+			Name:        "npm_test",
+			Description: "Run the unit tests with jest",
+			Command:     []string{"npm", "run", "test", "--", npmScriptArgs},
+			Dir:         absDir,
+		},
+	}, tools)
+
+	t.Run("reports invalid JSON", func(t *testing.T) {
+		broken := filepath.Join(dir, "broken.json")
+		require.NoError(t, os.WriteFile(broken, []byte(`{"studio": {"test": true}}`), 0644))
+		_, err := LoadPackageJSON(broken)
+		assert.ErrorContains(t, err, "invalid package.json")
+	})
+
+	t.Run("reports a missing package.json", func(t *testing.T) {
+		_, err := LoadPackageJSON(filepath.Join(dir, "missing.json"))
+		assert.ErrorContains(t, err, "failed to read package.json")
+	})
+}
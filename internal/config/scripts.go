@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"studio-mcp/internal/blueprint"
+)
+
+// scriptHeaderLines is how far into a script to look for header comments
+const scriptHeaderLines = 50
+
+// LoadScripts declares a tool for every executable file in dir. Scripts describe
+// their tool in comments at the top of the file:
+//
+//	#!/bin/bash
+//	# studio: {{env # target env}} [--dry-run]
+//	# description: Deploy the app
+//
+// The studio line is the blueprint after the script path, and may continue on more
+// studio lines. Scripts without one take no arguments. In scripts with one, name,
+// title and timeout lines set those options; keys are lowercase, so headers like
+// "# Name: backup.sh" are just comments. Hidden files and subdirectories are skipped.
+func LoadScripts(dir string) ([]Tool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scripts: %w", err)
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scripts: %w", err)
+	}
+
+	var tools []Tool
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(absDir, entry.Name())
+		// Stat follows symlinks so linked scripts are served too
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+
+		tool, err := loadScript(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, entry.Name()), err)
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// scriptOption is a name, title or timeout header line
+type scriptOption struct {
+	key, value string
+	line       int
+}
+
+// loadScript declares the tool for a script from its header comments
+func loadScript(path string) (Tool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Tool{}, fmt.Errorf("failed to read script: %w", err)
+	}
+	defer file.Close()
+
+	tool := Tool{Command: []string{path}}
+	var description []string
+	// Options only count in scripts with a studio line, so other scripts' headers
+	// can't break them
+	var options []scriptOption
+	hasStudio := false
+
+	scanner := bufio.NewScanner(file)
+	for n := 0; n < scriptHeaderLines && scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#!") {
+			continue
+		}
+		// The header ends at the first line of code
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+
+		key, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key = strings.TrimSpace(key); key {
+		case "studio":
+			words, err := blueprint.SplitWords(value)
+			if err != nil {
+				return Tool{}, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			tool.Command = append(tool.Command, words...)
+			hasStudio = true
+		case "description":
+			// Long descriptions can continue over several description lines
+			description = append(description, value)
+		case "name", "title", "timeout":
+			options = append(options, scriptOption{key: key, value: value, line: n + 1})
+		}
+	}
+	// Compiled programs may have no line breaks near the top, and have no header anyway
+	if err := scanner.Err(); err != nil && !errors.Is(err, bufio.ErrTooLong) {
+		return Tool{}, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	if !hasStudio {
+		options = nil
+	}
+	for _, option := range options {
+		switch option.key {
+		case "name":
+			tool.Name = option.value
+		case "title":
+			tool.Title = option.value
+		case "timeout":
+			timeout, err := ParseDuration(option.value)
+			if err != nil {
+				return Tool{}, fmt.Errorf("line %d: %w", option.line, err)
+			}
+			tool.Timeout = Duration(timeout)
+		}
+	}
+
+	tool.Description = strings.Join(description, " ")
+	return tool, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_LoadScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeScript := func(name, content string, mode os.FileMode) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), mode))
+	}
+
+	writeScript("deploy.sh", `#!/bin/bash
+# studio: {{env # target env}} [--dry-run # only print]
+# description: Deploy the app
+# description: to an environment.
+set -e
+# studio: not part of the header
+`, 0755)
+	writeScript("backup.py", `#!/usr/bin/env python3
+
+# name: backup_db
+# title: Back up the database
+# timeout: 10m
+# Anything else is just a comment
+# studio: "[tables... # tables to back up]"
+import sys
+`, 0755)
+	writeScript("plain", "#!/bin/sh\n# Name: plain.sh\n# Timeout: none\necho hello\n", 0755)
+	writeScript("tidy.sh", "#!/bin/sh\n# Name: tidy.sh\n# Author: me\n# studio: [path]\n", 0755)
+	writeScript("README.md", "# studio: {{ignored}}\n", 0644)
+	writeScript(".hidden", "#!/bin/sh\n", 0755)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lib"), 0755))
+
+	tools, err := LoadScripts(dir)
+	require.NoError(t, err)
+	require.Len(t, tools, 4)
+
+	absDir, err := filepath.Abs(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, Tool{
+		Name:    "backup_db",
+		Title:   "Back up the database",
+		Command: []string{filepath.Join(absDir, "backup.py"), "[tables... # tables to back up]"},
+		Timeout: Duration(10 * time.Minute),
+	}, tools[0])
+	assert.Equal(t, Tool{
+		Description: "Deploy the app to an environment.",
+		Command:     []string{filepath.Join(absDir, "deploy.sh"), "{{env # target env}}", "[--dry-run # only print]"},
+	}, tools[1])
+	assert.Equal(t, Tool{
+		Command: []string{filepath.Join(absDir, "plain")},
+	}, tools[2], "option headers only count with a studio line")
+	assert.Equal(t, Tool{
+		Command: []string{filepath.Join(absDir, "tidy.sh"), "[path]"},
+	}, tools[3], "capitalized headers are just comments")
+
+	t.Run("reports broken options in scripts with a studio line", func(t *testing.T) {
+		writeScript("slow.sh", "#!/bin/sh\n# timeout: none\n# studio: [path]\n", 0755)
+		defer os.Remove(filepath.Join(dir, "slow.sh"))
+		_, err := LoadScripts(dir)
+		assert.ErrorContains(t, err, "slow.sh: line 2:")
+	})
+
+	t.Run("reports broken headers with the script", func(t *testing.T) {
+		writeScript("broken.sh", "#!/bin/sh\n# studio: \"{{text}}\n", 0755)
+		_, err := LoadScripts(dir)
+		assert.ErrorContains(t, err, "broken.sh: line 2: unclosed \" quote")
+	})
+
+	t.Run("reports a missing directory", func(t *testing.T) {
+		_, err := LoadScripts(filepath.Join(dir, "missing"))
+		assert.ErrorContains(t, err, "failed to read scripts")
+	})
+}
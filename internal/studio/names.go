@@ -0,0 +1,59 @@
+package studio
+
+import (
+	"fmt"
+	"strings"
+	"studio-mcp/internal/blueprint"
+	"studio-mcp/internal/tool"
+)
+
+// nameTools gives every tool without an explicit name one generated from its base
+// command, then checks that every name is valid and unique. Tools that would share a
+// generated name are told apart by their first literal argument that isn't a flag,
+// so python report.py and python backup.py become python_report and python_backup.
+func nameTools(tools []Tool) error {
+	generated := make([]bool, len(tools))
+	counts := make(map[string]int, len(tools))
+	for i := range tools {
+		if tools[i].Options.Name == "" {
+			tools[i].Options.Name = tool.GenerateToolName(tools[i].Blueprint.GetBaseCommand())
+			generated[i] = true
+		}
+		counts[tools[i].Options.Name]++
+	}
+
+	for i := range tools {
+		if !generated[i] || counts[tools[i].Options.Name] < 2 {
+			continue
+		}
+		if qualifier := nameQualifier(tools[i].Blueprint); qualifier != "" {
+			tools[i].Options.Name = tool.GenerateToolName(tools[i].Options.Name + "_" + qualifier)
+		}
+	}
+
+	seen := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		if err := tool.ValidateToolName(t.Options.Name); err != nil {
+			return err
+		}
+		if seen[t.Options.Name] {
+			return fmt.Errorf("more than one tool is named %q; use --name or a name in the config to tell them apart", t.Options.Name)
+		}
+		seen[t.Options.Name] = true
+	}
+	return nil
+}
+
+// nameQualifier returns a tool name part for the first literal argument that isn't a flag
+func nameQualifier(t tool.Blueprint) string {
+	bp, ok := t.(*blueprint.Blueprint)
+	if !ok {
+		return ""
+	}
+	for _, arg := range bp.LiteralArgs() {
+		if !strings.HasPrefix(arg, "-") {
+			return tool.GenerateToolName(arg)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultReloadInterval is how often the sources are checked for changes
+const defaultReloadInterval = time.Second
+
+// version identifies the current state of the sources by the names, modification
+// times and sizes of their files, so adding, removing, editing or chmodding a script
+// changes it
+func (src Sources) version() (string, error) {
+	var version strings.Builder
+	for _, path := range []string{src.ConfigPath, src.Makefile, src.NPMScripts} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		writeFileVersion(&version, info)
+	}
+
+	if src.ScriptsDir != "" {
+		entries, err := os.ReadDir(src.ScriptsDir)
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range entries {
+			// Stat follows symlinks to notice edits to linked scripts
+			info, err := os.Stat(filepath.Join(src.ScriptsDir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			writeFileVersion(&version, info)
+		}
+	}
+	return version.String(), nil
+}
+
+// writeFileVersion writes what identifies a version of a file
+func writeFileVersion(version *strings.Builder, info os.FileInfo) {
+	fmt.Fprintf(version, "%s %s %d %d\n", info.Name(), info.Mode(), info.Size(), info.ModTime().UnixNano())
+}
+
+// watchSources polls the sources until ctx is done, reloading the tools whenever
+// they change
+func (s *Studio) watchSources(ctx context.Context, server *mcp.Server) {
+	interval := s.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	last, _ := s.sources.version()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Editors may briefly remove the file while saving, so wait for it to come back
+			current, err := s.sources.version()
+			if err != nil || current == last {
+				continue
+			}
+			last = current
+			err = s.reload(server)
+			if s.reloaded != nil {
+				s.reloaded(err)
+			}
+		}
+	}
+}
+
+// reload rebuilds the tools from their sources and swaps them on the server, which
+// tells clients the tool list changed. A broken source keeps the current tools.
+func (s *Studio) reload(server *mcp.Server) error {
+	tools, err := loadTools(s.sources)
+	if err != nil {
+		logf("Keeping the current tools, failed to reload: %s", err)
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.Tools
+	s.Tools = tools
+	s.mu.Unlock()
+
+	// Adding replaces tools with the same name, so only the rest need removing
+	server.AddTools(s.serverTools(tools)...)
+	if removed := removedToolNames(previous, tools); len(removed) > 0 {
+		server.RemoveTools(removed...)
+	}
+
+	if s.DebugMode {
+		logf("Reloaded %d tools", len(tools))
+	}
+	return nil
+}
+
+// removedToolNames returns the names of previous tools that are not in current
+func removedToolNames(previous, current []Tool) []string {
+	names := make(map[string]bool, len(current))
+	for _, t := range current {
+		names[t.Options.Name] = true
+	}
+
+	var removed []string
+	for _, t := range previous {
+		if !names[t.Options.Name] {
+			removed = append(removed, t.Options.Name)
+		}
+	}
+	return removed
+}
+
+// logf logs a message to stderr, which MCP clients show in their server logs
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "[Studio MCP] "+format+"\n", args...)
+}
@@ -0,0 +1,135 @@
+package studio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectClient serves s over an in-memory transport and returns a connected client
+// session and a channel that receives each tools/list_changed notification
+func connectClient(t *testing.T, s *Studio) (*mcp.ClientSession, <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	go s.serve(ctx, serverTransport)
+
+	changed := make(chan struct{}, 10)
+	client := mcp.NewClient("test-client", "1.0.0", &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ClientSession, *mcp.ToolListChangedParams) {
+			changed <- struct{}{}
+		},
+	})
+
+	session, err := client.Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	return session, changed
+}
+
+// toolNames lists the names of the tools served to a client session
+func toolNames(t *testing.T, session *mcp.ClientSession) []string {
+	result, err := session.ListTools(context.Background(), &mcp.ListToolsParams{})
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestStudio_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	writeConfig := func(data string) {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	}
+
+	writeConfig("tools:\n  - command: [echo, '{{text}}']\n  - command: [date]\n")
+	s, err := NewFromSources(Sources{ConfigPath: path, Commands: []Command{{Args: []string{"pwd"}}}}, false, "test")
+	require.NoError(t, err)
+	s.ReloadInterval = 10 * time.Millisecond
+	reloads := make(chan error, 10)
+	s.reloaded = func(err error) { reloads <- err }
+
+	session, changed := connectClient(t, s)
+	assert.Equal(t, []string{"date", "echo", "pwd"}, toolNames(t, session))
+
+	t.Run("swaps the tools when the config changes", func(t *testing.T) {
+		writeConfig("tools:\n  - command: [echo, '{{text}}']\n  - name: list\n    command: [ls, '[path]']\n")
+
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no tools/list_changed notification")
+		}
+		assert.Eventually(t, func() bool {
+			return assert.ObjectsAreEqual([]string{"echo", "list", "pwd"}, toolNames(t, session))
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("keeps the current tools when the config is broken", func(t *testing.T) {
+		writeConfig("tools:\n  - command: [echo, '{{text']\n")
+
+		// Skip the reload of the last change, if it's still pending
+		timeout := time.After(5 * time.Second)
+		for failed := false; !failed; {
+			select {
+			case err := <-reloads:
+				failed = err != nil
+			case <-timeout:
+				t.Fatal("the watcher never tried the broken config")
+			}
+		}
+		assert.Equal(t, []string{"echo", "list", "pwd"}, toolNames(t, session))
+	})
+}
+
+func TestStudio_RemovedToolNames(t *testing.T) {
+	named := func(names ...string) []Tool {
+		tools := make([]Tool, len(names))
+		for i, name := range names {
+			tools[i].Options.Name = name
+		}
+		return tools
+	}
+
+	assert.Equal(t, []string{"b", "d"}, removedToolNames(named("a", "b", "c", "d"), named("c", "a", "e")))
+	assert.Empty(t, removedToolNames(named("a"), named("a", "b")))
+}
+
+func TestStudio_ReloadScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeScript := func(name, header string) {
+		content := "#!/bin/sh\n" + header + "\necho \"$@\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0755))
+	}
+
+	writeScript("greet.sh", "# studio: {{name}}")
+	s, err := NewFromSources(Sources{ScriptsDir: dir}, false, "test")
+	require.NoError(t, err)
+	s.ReloadInterval = 10 * time.Millisecond
+
+	session, changed := connectClient(t, s)
+	assert.Equal(t, []string{"greet"}, toolNames(t, session))
+
+	writeScript("deploy.sh", "# studio: {{env # target env}} [--dry-run]\n# description: Deploy the app")
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tools/list_changed notification")
+	}
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"deploy", "greet"}, toolNames(t, session))
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,61 @@
+package studio
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errShuttingDown is the cause of requests cancelled because the server is stopping
+var errShuttingDown = errors.New("server shutting down")
+
+// inFlight tracks the requests being handled so shutdown can wait for them
+type inFlight struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	closing bool
+}
+
+// start records a request, unless shutdown has begun
+func (f *inFlight) start() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closing {
+		return false
+	}
+	f.wg.Add(1)
+	return true
+}
+
+// wait refuses new requests and waits for the ones in flight
+func (f *inFlight) wait() {
+	f.mu.Lock()
+	f.closing = true
+	f.mu.Unlock()
+	f.wg.Wait()
+}
+
+// cancelOnShutdown cancels every request in flight, along with the commands they run,
+// once shutdown is done. Cancelled tool calls from the client already cancel their
+// request's context.
+func cancelOnShutdown(shutdown context.Context, requests *inFlight) mcp.Middleware[*mcp.ServerSession] {
+	return func(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+		return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+			if !requests.start() {
+				return nil, errShuttingDown
+			}
+			defer requests.wg.Done()
+
+			ctx, cancel := context.WithCancelCause(ctx)
+			defer cancel(nil)
+			stop := context.AfterFunc(shutdown, func() {
+				cancel(errShuttingDown)
+			})
+			defer stop()
+
+			return next(ctx, session, method, params)
+		}
+	}
+}
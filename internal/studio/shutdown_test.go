@@ -0,0 +1,75 @@
+package studio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStudio_StopsAbandonedCommands(t *testing.T) {
+	// startTool serves a tool that reports when it starts and when it gets SIGTERM
+	startTool := func(t *testing.T) (shutdown context.CancelFunc, served <-chan error, session *mcp.ClientSession, started, stopped string) {
+		dir := t.TempDir()
+		started, stopped = filepath.Join(dir, "started"), filepath.Join(dir, "stopped")
+		script := "trap 'touch " + stopped + "; exit 1' TERM; touch " + started + "; sleep 30 & wait"
+
+		s, err := NewFromCommands([]Command{{Args: []string{"sh", "-c", script}}}, false, "test")
+		require.NoError(t, err)
+
+		ctx, shutdown := context.WithCancel(context.Background())
+		t.Cleanup(shutdown)
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		done := make(chan error, 1)
+		go func() {
+			done <- s.serve(ctx, serverTransport)
+		}()
+
+		session, err = mcp.NewClient("test-client", "1.0.0", nil).Connect(context.Background(), clientTransport)
+		require.NoError(t, err)
+		return shutdown, done, session, started, stopped
+	}
+
+	t.Run("when the client cancels the call", func(t *testing.T) {
+		_, _, session, started, stopped := startTool(t)
+		defer session.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			assert.Eventually(t, func() bool { return fileExists(started) }, 5*time.Second, 10*time.Millisecond)
+			cancel()
+		}()
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "sh"})
+		assert.Error(t, err)
+
+		assert.Eventually(t, func() bool { return fileExists(stopped) }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("when the server shuts down", func(t *testing.T) {
+		shutdown, served, session, started, stopped := startTool(t)
+
+		go session.CallTool(context.Background(), &mcp.CallToolParams{Name: "sh"})
+		require.Eventually(t, func() bool { return fileExists(started) }, 5*time.Second, 10*time.Millisecond)
+		shutdown()
+
+		select {
+		case err := <-served:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("serve didn't return after shutting down")
+		}
+		assert.True(t, fileExists(stopped), "the command didn't get SIGTERM")
+	})
+}
+
+// fileExists reports whether a file has been created
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -3,17 +3,67 @@ package studio
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"studio-mcp/internal/blueprint"
+	"studio-mcp/internal/config"
 	"studio-mcp/internal/tool"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// Tool is a blueprint, or a dispatch between several, served as an MCP tool
+type Tool struct {
+	Blueprint tool.Blueprint
+	Options   tool.Options
+
+	subcommand string // Subcommand the tool was fanned out for, if any
+}
+
+// Command is a blueprint given on the command line with the options for its tool
+type Command struct {
+	Args    []string
+	Options tool.Options
+}
+
+// Sources are the places a Studio loads its tools from, and the defaults for them
+type Sources struct {
+	ConfigPath string    // YAML or JSON file declaring tools
+	ScriptsDir string    // Directory of executable scripts, one tool each
+	Makefile   string    // Makefile whose documented targets are tools
+	NPMScripts string    // package.json whose scripts are tools
+	Commands   []Command // Blueprints given on the command line
+
+	Timeout        time.Duration // Timeout for tools that don't declare their own
+	MaxOutputLines int           // Most lines of output in a tool result; zero means no limit
+	MaxOutputBytes int           // Most bytes of output in a tool result; zero means 512 KB
+}
+
+// limitsOutput reports whether tool results are truncated, which serves read_output
+func (src Sources) limitsOutput() bool {
+	return src.MaxOutputLines > 0 || src.MaxOutputBytes > 0
+}
+
+// watched reports whether any source can change while serving
+func (src Sources) watched() bool {
+	return src.ConfigPath != "" || src.ScriptsDir != "" || src.Makefile != "" || src.NPMScripts != ""
+}
+
 // Studio represents the main application logic
 type Studio struct {
-	Blueprint *blueprint.Blueprint
-	DebugMode bool
-	Version   string
+	Tools          []Tool
+	DebugMode      bool
+	Version        string
+	ReloadInterval time.Duration // How often to check the sources for changes
+
+	sources Sources           // Where the tools were loaded from
+	outputs *tool.OutputStore // Full output of truncated results, when output is limited
+	mu      sync.Mutex
+
+	reloaded func(error) // Called after the watcher tries a reload, for tests
 }
 
 // New creates a new Studio instance from command arguments
@@ -22,31 +72,260 @@ func New(args []string, debugMode bool, version string) (*Studio, error) {
 		return nil, fmt.Errorf("no command provided")
 	}
 
-	bp, err := blueprint.FromArgs(args)
+	return NewFromCommands([]Command{{Args: args}}, debugMode, version)
+}
+
+// NewFromCommands creates a new Studio instance serving one tool per command
+func NewFromCommands(commands []Command, debugMode bool, version string) (*Studio, error) {
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("no command provided")
+	}
+
+	return NewFromSources(Sources{Commands: commands}, debugMode, version)
+}
+
+// NewFromSources creates a new Studio instance serving every tool in a config file,
+// every script in a directory, the documented targets of a Makefile, the scripts in a
+// package.json and one tool per command. Everything but the commands is reloaded when
+// it changes while serving.
+func NewFromSources(sources Sources, debugMode bool, version string) (*Studio, error) {
+	tools, err := loadTools(sources)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create blueprint: %w", err)
+		return nil, err
 	}
 
 	// Set debug mode on tool
 	tool.SetDebugMode(debugMode)
 
-	return &Studio{
-		Blueprint: bp,
+	s := &Studio{
+		Tools:     tools,
 		DebugMode: debugMode,
 		Version:   version,
+		sources:   sources,
+	}
+	if sources.limitsOutput() {
+		s.outputs = tool.NewOutputStore(sources.MaxOutputLines, sources.MaxOutputBytes)
+	}
+	return s, nil
+}
+
+// loadTools builds the tools declared in the config file, scripts, Makefile and
+// package.json, if any, and one for each command
+func loadTools(sources Sources) ([]Tool, error) {
+	var declared []config.Tool
+	if sources.ConfigPath != "" {
+		cfg, err := config.Load(sources.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		declared = append(declared, cfg.Tools...)
+	}
+	if sources.ScriptsDir != "" {
+		scripts, err := config.LoadScripts(sources.ScriptsDir)
+		if err != nil {
+			return nil, err
+		}
+		declared = append(declared, scripts...)
+	}
+	if sources.Makefile != "" {
+		targets, err := config.LoadMakefile(sources.Makefile)
+		if err != nil {
+			return nil, err
+		}
+		declared = append(declared, targets...)
+	}
+	if sources.NPMScripts != "" {
+		scripts, err := config.LoadPackageJSON(sources.NPMScripts)
+		if err != nil {
+			return nil, err
+		}
+		declared = append(declared, scripts...)
+	}
+
+	tools, err := toolsFromConfig(declared)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := sources.Commands
+	for i, command := range commands {
+		bp, err := blueprint.FromArgsStrict(command.Args)
+		if err != nil {
+			if len(commands) > 1 {
+				return nil, fmt.Errorf("failed to create blueprint for tool %d: %w", i+1, err)
+			}
+			return nil, fmt.Errorf("failed to create blueprint: %w", err)
+		}
+		tools = append(tools, Tool{Blueprint: bp, Options: command.Options})
+	}
+
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("no tools to serve")
+	}
+	if err := nameTools(tools); err != nil {
+		return nil, err
+	}
+	if sources.limitsOutput() {
+		for _, t := range tools {
+			if t.Options.Name == tool.ReadOutputToolName {
+				return nil, fmt.Errorf("the tool name %q is taken by the tool that reads truncated output; use --name or a name in the config to rename the tool", tool.ReadOutputToolName)
+			}
+		}
+	}
+	inferAnnotations(tools)
+
+	for i := range tools {
+		if tools[i].Options.Timeout == 0 {
+			tools[i].Options.Timeout = sources.Timeout
+		}
+	}
+
+	return tools, nil
+}
+
+// inferAnnotations fills in the hints tools don't declare from the known commands
+// table, for tools that ask for it. Dispatch tools run several commands, so they only
+// have the hints they declare.
+func inferAnnotations(tools []Tool) {
+	for i, t := range tools {
+		bp, ok := t.Blueprint.(*blueprint.Blueprint)
+		if !ok || !t.Options.InferAnnotations {
+			continue
+		}
+
+		// Global flags can come before a fanned out subcommand, so use it directly
+		args := bp.LiteralArgs()
+		if t.subcommand != "" {
+			args = []string{t.subcommand}
+		}
+		inferred := tool.InferAnnotations(bp.GetBaseCommand(), args)
+		tools[i].Options.Annotations = inferred.Merge(t.Options.Annotations)
+	}
+}
+
+// toolsFromConfig builds the tools declared in a config file, scripts, a Makefile or a
+// package.json
+func toolsFromConfig(declaredTools []config.Tool) ([]Tool, error) {
+	tools := make([]Tool, 0, len(declaredTools))
+	for _, declared := range declaredTools {
+		if declared.Dispatch {
+			t, err := dispatchTool(declared)
+			if err != nil {
+				return nil, err
+			}
+			tools = append(tools, t)
+			continue
+		}
+
+		if len(declared.Subcommands) > 0 {
+			for _, sub := range declared.Subcommands {
+				t, err := toolFromConfig(subcommandTool(declared, sub))
+				if err != nil {
+					return nil, err
+				}
+				t.subcommand = sub.Command[0]
+				tools = append(tools, t)
+			}
+			continue
+		}
+
+		t, err := toolFromConfig(declared)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
+
+// toolFromConfig builds a single declared tool
+func toolFromConfig(declared config.Tool) (Tool, error) {
+	bp, err := blueprint.FromArgsStrict(declared.Command)
+	if err != nil {
+		name := declared.Name
+		if name == "" {
+			name = tool.GenerateToolName(declared.Command[0])
+		}
+		return Tool{}, fmt.Errorf("failed to create blueprint for tool %q: %w", name, err)
+	}
+
+	return Tool{
+		Blueprint: bp,
+		Options: tool.Options{
+			Name:        declared.Name,
+			Title:       declared.Title,
+			Description: declared.Description,
+			Dir:         declared.Dir,
+			Env:         declared.Environ(),
+			Timeout:     time.Duration(declared.Timeout),
+
+			Annotations:      tool.Annotations(declared.Annotations),
+			InferAnnotations: declared.InferAnnotations,
+		},
 	}, nil
 }
 
-// Serve starts the MCP server over stdio
+// Serve starts the MCP server over stdio until stdin closes, or until SIGINT or
+// SIGTERM, which stop any commands still running first
 func (s *Studio) Serve() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.serve(ctx, mcp.NewStdioTransport())
+}
+
+// serve runs the MCP server over the given transport, reloading the tools when their
+// sources change. Once ctx is done, the commands still running are stopped and serve
+// returns when they have.
+func (s *Studio) serve(ctx context.Context, transport mcp.Transport) error {
+	server := s.newServer()
+
+	var requests inFlight
+	server.AddReceivingMiddleware(cancelOnShutdown(ctx, &requests))
+	// Remove the temporary files of outputs kept for read_output
+	defer s.outputs.Close()
+
+	if s.sources.watched() {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go s.watchSources(ctx, server)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx, transport)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		requests.wait()
+		return nil
+	}
+}
+
+// newServer creates an MCP server with every tool added
+func (s *Studio) newServer() *mcp.Server {
 	// Create server with version from build
 	server := mcp.NewServer("studio-mcp", s.Version, nil)
 
-	// Add the tool to the server using CreateServerTool from tool package
-	serverTool := tool.CreateServerTool(s.Blueprint)
+	// Add the tools to the server using NewServerTool from tool package
+	server.AddTools(s.serverTools(s.Tools)...)
+	if s.outputs != nil {
+		server.AddTools(tool.NewReadOutputTool(s.outputs))
+	}
 
-	server.AddTools(serverTool)
+	return server
+}
 
-	// Run the server over stdio
-	return server.Run(context.Background(), mcp.NewStdioTransport())
+// serverTools creates the MCP server tools for the given tools, which share the
+// studio's output store
+func (s *Studio) serverTools(tools []Tool) []*mcp.ServerTool {
+	result := make([]*mcp.ServerTool, len(tools))
+	for i, t := range tools {
+		opts := t.Options
+		opts.Outputs = s.outputs
+		result[i] = tool.NewServerTool(t.Blueprint, opts)
+	}
+	return result
 }
@@ -0,0 +1,52 @@
+package studio
+
+import (
+	"context"
+	"studio-mcp/internal/tool"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStudio_OutputLimits(t *testing.T) {
+	t.Run("truncates results and serves read_output", func(t *testing.T) {
+		s, err := NewFromSources(Sources{
+			Commands:       []Command{{Args: []string{"seq", "10"}}},
+			MaxOutputLines: 4,
+		}, false, "test")
+		require.NoError(t, err)
+
+		session, _ := connectClient(t, s)
+		assert.Equal(t, []string{"read_output", "seq"}, toolNames(t, session))
+
+		call := func(name string, arguments map[string]any) string {
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: arguments})
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			return result.Content[0].(*mcp.TextContent).Text
+		}
+
+		assert.Equal(t, "1\n2\n… 6 lines omitted …\n9\n10\n\n"+
+			`[Output truncated from 10 lines and 20 bytes. Call read_output with id "out-1" to read all of it.]`, call("seq", map[string]any{}))
+		assert.Equal(t, "3\n4\n5\n6\n\n[Lines 3-6 of 10. Call read_output with offset 6 for more.]",
+			call("read_output", map[string]any{"id": "out-1", "offset": 2}))
+	})
+
+	t.Run("serves output whole without limits", func(t *testing.T) {
+		s, err := NewFromSources(Sources{Commands: []Command{{Args: []string{"seq", "10"}}}}, false, "test")
+		require.NoError(t, err)
+
+		session, _ := connectClient(t, s)
+		assert.Equal(t, []string{"seq"}, toolNames(t, session))
+	})
+
+	t.Run("keeps the read_output name for itself", func(t *testing.T) {
+		_, err := NewFromSources(Sources{
+			Commands:       []Command{{Args: []string{"cat", "{{file}}"}, Options: tool.Options{Name: "read_output"}}},
+			MaxOutputBytes: 1000,
+		}, false, "test")
+		assert.EqualError(t, err, `the tool name "read_output" is taken by the tool that reads truncated output; use --name or a name in the config to rename the tool`)
+	})
+}
@@ -0,0 +1,112 @@
+package studio
+
+import (
+	"fmt"
+	"strings"
+	"studio-mcp/internal/blueprint"
+	"studio-mcp/internal/config"
+	"studio-mcp/internal/tool"
+	"time"
+)
+
+// subcommandTool declares the tool for one subcommand of a fanned out tool. It runs the
+// parent's command, including its global flags, followed by the subcommand's words,
+// and is named after both: git with a status subcommand is git_status, and gh with
+// pr list is gh_pr_list. The subcommand inherits the parent's working directory,
+// environment, timeout and annotations unless it declares its own.
+func subcommandTool(parent, sub config.Tool) config.Tool {
+	prefix := parent.Name
+	if prefix == "" {
+		prefix = tool.GenerateToolName(parent.Command[0])
+	}
+	name := subcommandName(sub)
+
+	env := make(map[string]string, len(parent.Env)+len(sub.Env))
+	for key, value := range parent.Env {
+		env[key] = value
+	}
+	for key, value := range sub.Env {
+		env[key] = value
+	}
+
+	dir := sub.Dir
+	if dir == "" {
+		dir = parent.Dir
+	}
+	timeout := sub.Timeout
+	if timeout == 0 {
+		timeout = parent.Timeout
+	}
+
+	annotations := tool.Annotations(parent.Annotations).Merge(tool.Annotations(sub.Annotations))
+
+	return config.Tool{
+		Name:        prefix + "_" + name,
+		Title:       sub.Title,
+		Description: sub.Description,
+		Command:     append(append([]string{}, parent.Command...), sub.Command...),
+		Dir:         dir,
+		Env:         env,
+		Timeout:     timeout,
+
+		Annotations:      config.Annotations(annotations),
+		InferAnnotations: parent.InferAnnotations || sub.InferAnnotations,
+	}
+}
+
+// subcommandName names a subcommand by its name or its leading literal words
+func subcommandName(sub config.Tool) string {
+	if sub.Name != "" {
+		return sub.Name
+	}
+	return tool.GenerateToolName(strings.Join(subcommandWords(sub.Command), "_"))
+}
+
+// dispatchTool builds the single tool for a tool that dispatches to its subcommands.
+// Each subcommand becomes an action, chosen by the action field, that runs the
+// command followed by the subcommand's words.
+func dispatchTool(declared config.Tool) (Tool, error) {
+	name := declared.Name
+	if name == "" {
+		name = tool.GenerateToolName(declared.Command[0])
+	}
+
+	actions := make([]blueprint.Action, len(declared.Subcommands))
+	for i, sub := range declared.Subcommands {
+		action := subcommandName(sub)
+		bp, err := blueprint.FromArgsStrict(append(append([]string{}, declared.Command...), sub.Command...))
+		if err != nil {
+			return Tool{}, fmt.Errorf("failed to create blueprint for action %q of tool %q: %w", action, name, err)
+		}
+		actions[i] = blueprint.Action{Name: action, Description: sub.Description, Blueprint: bp}
+	}
+
+	dispatch, err := blueprint.NewDispatch(actions)
+	if err != nil {
+		return Tool{}, fmt.Errorf("tool %q: %w", name, err)
+	}
+
+	return Tool{
+		Blueprint: dispatch,
+		Options: tool.Options{
+			Name:        declared.Name,
+			Title:       declared.Title,
+			Description: declared.Description,
+			Dir:         declared.Dir,
+			Env:         declared.Environ(),
+			Timeout:     time.Duration(declared.Timeout),
+			Annotations: tool.Annotations(declared.Annotations),
+		},
+	}, nil
+}
+
+// subcommandWords returns the literal words that name a subcommand, up to the first
+// flag or field
+func subcommandWords(command []string) []string {
+	for i, word := range command {
+		if strings.HasPrefix(word, "-") || strings.ContainsAny(word, "{[") {
+			return command[:i]
+		}
+	}
+	return command
+}
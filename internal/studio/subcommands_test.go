@@ -0,0 +1,114 @@
+package studio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStudio_Subcommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  - command: [git, "[-C {{repo # repository directory}}]"]
+    env: {GIT_PAGER: cat, LANG: C}
+    timeout: 30s
+    inferAnnotations: true
+    subcommands:
+      - command: [status, "[--short]"]
+      - name: history
+        description: Show recent commits
+        command: [log, --oneline, "[-n {{count: integer}}]"]
+        env: {LANG: en_US.UTF-8}
+        timeout: 1m
+  - name: github
+    command: [gh]
+    subcommands:
+      - command: [pr, list, "[--state {{state: open|closed}}]"]
+        annotations: {readOnlyHint: true}
+`), 0644))
+
+	tools, err := loadTools(Sources{ConfigPath: path, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	require.Len(t, tools, 3)
+
+	status, history, prList := tools[0], tools[1], tools[2]
+
+	assert.Equal(t, "git_status", status.Options.Name)
+	assert.Equal(t, "git [-C {{repo}}] status [--short]", status.Blueprint.GetCommandFormat())
+	assert.Equal(t, []string{"GIT_PAGER=cat", "LANG=C"}, status.Options.Env)
+
+	t.Run("merges global flags into each schema", func(t *testing.T) {
+		args, err := history.Blueprint.BuildCommandArgs(map[string]interface{}{"repo": "/src", "count": 5})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"git", "-C", "/src", "log", "--oneline", "-n", "5"}, args)
+	})
+
+	t.Run("subcommands inherit the parent's timeout, or the default", func(t *testing.T) {
+		assert.Equal(t, 30*time.Second, status.Options.Timeout)
+		assert.Equal(t, 5*time.Second, prList.Options.Timeout)
+	})
+
+	t.Run("subcommand options win over the parent's", func(t *testing.T) {
+		assert.Equal(t, "git_history", history.Options.Name)
+		assert.Equal(t, time.Minute, history.Options.Timeout)
+		assert.Equal(t, "Show recent commits", history.Options.Description)
+		assert.Equal(t, []string{"GIT_PAGER=cat", "LANG=en_US.UTF-8"}, history.Options.Env)
+	})
+
+	t.Run("infers annotations from the subcommand", func(t *testing.T) {
+		require.NotNil(t, status.Options.Annotations.ReadOnly)
+		assert.True(t, *status.Options.Annotations.ReadOnly)
+	})
+
+	t.Run("names tools after every subcommand word", func(t *testing.T) {
+		assert.Equal(t, "github_pr_list", prList.Options.Name)
+		require.NotNil(t, prList.Options.Annotations.ReadOnly)
+		assert.True(t, *prList.Options.Annotations.ReadOnly)
+	})
+}
+
+func TestStudio_Dispatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  - name: greet
+    command: [echo]
+    dispatch: true
+    annotations: {readOnlyHint: true}
+    subcommands:
+      - command: [hello, "{{name}}"]
+      - name: bye
+        description: Say goodbye
+        command: [goodbye, "[--loud]"]
+`), 0644))
+
+	s, err := NewFromSources(Sources{ConfigPath: path}, false, "test")
+	require.NoError(t, err)
+	require.Len(t, s.Tools, 1)
+	assert.Equal(t, "greet", s.Tools[0].Options.Name)
+
+	session, _ := connectClient(t, s)
+	assert.Equal(t, []string{"greet"}, toolNames(t, session))
+
+	call := func(arguments map[string]any) (string, bool) {
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "greet", Arguments: arguments})
+		require.NoError(t, err)
+		require.Len(t, result.Content, 1)
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	output, isError := call(map[string]any{"action": "hello", "name": "world"})
+	assert.False(t, isError)
+	assert.Equal(t, "hello world", output)
+
+	output, isError = call(map[string]any{"action": "bye", "loud": true})
+	assert.False(t, isError)
+	assert.Equal(t, "goodbye --loud", output)
+}
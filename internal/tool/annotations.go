@@ -0,0 +1,104 @@
+package tool
+
+import (
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Annotations are hints for clients about what a tool does. Nil means not declared,
+// which clients treat as the MCP defaults: not read-only, destructive, not idempotent
+// and open world.
+type Annotations struct {
+	ReadOnly    *bool // Only reads its environment
+	Destructive *bool // May delete or overwrite things (only meaningful when not read-only)
+	Idempotent  *bool // Calling it again with the same arguments has no further effect
+	OpenWorld   *bool // Reaches outside the local machine, like the network
+}
+
+// Merge returns the annotations with every hint declared in overrides replaced
+func (a Annotations) Merge(overrides Annotations) Annotations {
+	if overrides.ReadOnly != nil {
+		a.ReadOnly = overrides.ReadOnly
+	}
+	if overrides.Destructive != nil {
+		a.Destructive = overrides.Destructive
+	}
+	if overrides.Idempotent != nil {
+		a.Idempotent = overrides.Idempotent
+	}
+	if overrides.OpenWorld != nil {
+		a.OpenWorld = overrides.OpenWorld
+	}
+	return a
+}
+
+// toolAnnotations converts the annotations for an MCP tool, or nil if none are declared
+func (a Annotations) toolAnnotations() *mcp.ToolAnnotations {
+	if a == (Annotations{}) {
+		return nil
+	}
+	return &mcp.ToolAnnotations{
+		ReadOnlyHint:    a.ReadOnly != nil && *a.ReadOnly,
+		DestructiveHint: a.Destructive,
+		IdempotentHint:  a.Idempotent != nil && *a.Idempotent,
+		OpenWorldHint:   a.OpenWorld,
+	}
+}
+
+// hint returns a pointer to a hint value
+func hint(value bool) *bool {
+	return &value
+}
+
+var (
+	// readOnlyCommand only reads local files or system state
+	readOnlyCommand = Annotations{ReadOnly: hint(true), Destructive: hint(false), Idempotent: hint(true), OpenWorld: hint(false)}
+	// destructiveCommand deletes or overwrites local things
+	destructiveCommand = Annotations{ReadOnly: hint(false), Destructive: hint(true), OpenWorld: hint(false)}
+	// destructiveRemoteCommand deletes or overwrites things elsewhere
+	destructiveRemoteCommand = Annotations{ReadOnly: hint(false), Destructive: hint(true), OpenWorld: hint(true)}
+	// networkCommand talks to other machines
+	networkCommand = Annotations{OpenWorld: hint(true)}
+)
+
+// knownCommands holds annotations for common commands, by command name or by command
+// and subcommand. Anything not listed here is left for the client to decide.
+var knownCommands = map[string]Annotations{
+	"cat": readOnlyCommand, "date": readOnlyCommand, "df": readOnlyCommand, "diff": readOnlyCommand,
+	"du": readOnlyCommand, "echo": readOnlyCommand, "file": readOnlyCommand, "grep": readOnlyCommand,
+	"head": readOnlyCommand, "hostname": readOnlyCommand, "jq": readOnlyCommand, "ls": readOnlyCommand,
+	"pwd": readOnlyCommand, "rg": readOnlyCommand, "stat": readOnlyCommand, "tail": readOnlyCommand,
+	"tree": readOnlyCommand, "uname": readOnlyCommand, "uptime": readOnlyCommand, "wc": readOnlyCommand,
+	"which": readOnlyCommand, "whoami": readOnlyCommand,
+
+	"git blame": readOnlyCommand, "git diff": readOnlyCommand, "git log": readOnlyCommand,
+	"git show": readOnlyCommand, "git status": readOnlyCommand,
+	"docker images": readOnlyCommand, "docker ps": readOnlyCommand,
+
+	"dd": destructiveCommand, "kill": destructiveCommand, "killall": destructiveCommand,
+	"mv": destructiveCommand, "pkill": destructiveCommand, "rm": destructiveCommand,
+	"rmdir": destructiveCommand, "shred": destructiveCommand, "truncate": destructiveCommand,
+	"git checkout": destructiveCommand, "git clean": destructiveCommand, "git rebase": destructiveCommand,
+	"git reset": destructiveCommand, "git restore": destructiveCommand,
+	"docker rm": destructiveCommand, "docker rmi": destructiveCommand,
+
+	"git push": destructiveRemoteCommand, "kubectl delete": destructiveRemoteCommand,
+
+	"curl": networkCommand, "gh": networkCommand, "git fetch": networkCommand, "git pull": networkCommand,
+	"nc": networkCommand, "ping": networkCommand, "scp": networkCommand, "ssh": networkCommand,
+	"wget": networkCommand,
+}
+
+// InferAnnotations guesses annotations for a command from the known commands table,
+// using the base command and its first argument as a subcommand: git push is
+// destructive, git status is read-only.
+func InferAnnotations(baseCommand string, args []string) Annotations {
+	name := filepath.Base(baseCommand)
+	if len(args) > 0 {
+		if annotations, ok := knownCommands[name+" "+args[0]]; ok {
+			return annotations
+		}
+	}
+	return knownCommands[name]
+}
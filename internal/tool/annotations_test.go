@@ -0,0 +1,84 @@
+package tool
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTool_InferAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseCommand string
+		args        []string
+		expected    Annotations
+	}{
+		{
+			name:        "read-only command",
+			baseCommand: "ls",
+			args:        []string{"-la"},
+			expected:    readOnlyCommand,
+		},
+		{
+			name:        "command given as a path",
+			baseCommand: "/bin/rm",
+			expected:    destructiveCommand,
+		},
+		{
+			name:        "subcommand",
+			baseCommand: "git",
+			args:        []string{"push"},
+			expected:    destructiveRemoteCommand,
+		},
+		{
+			name:        "read-only subcommand",
+			baseCommand: "git",
+			args:        []string{"status", "--short"},
+			expected:    readOnlyCommand,
+		},
+		{
+			name:        "unknown subcommand",
+			baseCommand: "git",
+			args:        []string{"commit"},
+			expected:    Annotations{},
+		},
+		{
+			name:        "unknown command",
+			baseCommand: "say",
+			expected:    Annotations{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, InferAnnotations(tt.baseCommand, tt.args))
+		})
+	}
+}
+
+func TestTool_Annotations(t *testing.T) {
+	t.Run("merges declared hints over inferred ones", func(t *testing.T) {
+		merged := destructiveCommand.Merge(Annotations{Destructive: hint(false), Idempotent: hint(true)})
+		assert.Equal(t, Annotations{ReadOnly: hint(false), Destructive: hint(false), Idempotent: hint(true), OpenWorld: hint(false)}, merged)
+	})
+
+	t.Run("converts to MCP tool annotations", func(t *testing.T) {
+		assert.Nil(t, Annotations{}.toolAnnotations())
+		assert.Equal(t, &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: hint(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   hint(false),
+		}, readOnlyCommand.toolAnnotations())
+		assert.Equal(t, &mcp.ToolAnnotations{DestructiveHint: hint(true)}, Annotations{Destructive: hint(true)}.toolAnnotations())
+	})
+
+	t.Run("are attached to server tools", func(t *testing.T) {
+		serverTool := NewServerTool(&MockBlueprint{}, Options{Annotations: Annotations{ReadOnly: hint(true)}})
+		assert.Equal(t, &mcp.ToolAnnotations{ReadOnlyHint: true}, serverTool.Tool.Annotations)
+
+		serverTool = NewServerTool(&MockBlueprint{}, Options{})
+		assert.Nil(t, serverTool.Tool.Annotations)
+	})
+}
@@ -0,0 +1,293 @@
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// spillThreshold is how much of a stream's output is kept in memory. Beyond it the
+// output moves to a temporary file, so a command that prints gigabytes doesn't take
+// gigabytes of memory.
+var spillThreshold = 256 << 10
+
+// capture keeps what one of a command's streams prints: in memory at first, then in a
+// temporary file once there's more than spillThreshold
+type capture struct {
+	memory bytes.Buffer
+	file   *os.File
+	writer *bufio.Writer // Buffers writes to the file
+	size   int64
+	err    error // The first error spilling to the file; later output is dropped
+}
+
+// Write keeps p. It never fails, so the command can keep printing even if the output
+// can't be kept.
+func (c *capture) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return len(p), nil
+	}
+	if c.file == nil && c.memory.Len()+len(p) > spillThreshold {
+		c.spill()
+		if c.err != nil {
+			return len(p), nil
+		}
+	}
+
+	if c.file == nil {
+		c.memory.Write(p)
+	} else if _, err := c.writer.Write(p); err != nil {
+		c.fail(err)
+		return len(p), nil
+	}
+	c.size += int64(len(p))
+	return len(p), nil
+}
+
+// spill moves the output kept in memory to a temporary file. If it can't, the output
+// stays in memory and the rest is dropped.
+func (c *capture) spill() {
+	file, err := os.CreateTemp("", "studio-mcp-output-*")
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	debug("Output is over %d bytes, moving it to %s", spillThreshold, file.Name())
+
+	writer := bufio.NewWriterSize(file, 32<<10)
+	if _, err := writer.Write(c.memory.Bytes()); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		c.fail(err)
+		return
+	}
+	c.file, c.writer = file, writer
+	c.memory = bytes.Buffer{}
+}
+
+// fail stops keeping output after an error writing the temporary file
+func (c *capture) fail(err error) {
+	if c.file != nil {
+		// Only what made it to the file can be read back
+		if info, statErr := c.file.Stat(); statErr == nil {
+			c.size = min(c.size, info.Size())
+		}
+	}
+	debug("Dropping output after %d bytes, failed to keep it: %s", c.size, err)
+	c.err = err
+}
+
+// section returns a reader for everything kept so far
+func (c *capture) section() *io.SectionReader {
+	if c.file == nil {
+		return io.NewSectionReader(bytes.NewReader(c.memory.Bytes()), 0, c.size)
+	}
+	if err := c.writer.Flush(); err != nil && c.err == nil {
+		c.fail(err)
+	}
+	return io.NewSectionReader(c.file, 0, c.size)
+}
+
+// close removes the temporary file, if there is one
+func (c *capture) close() {
+	if c.file != nil {
+		c.file.Close()
+		os.Remove(c.file.Name())
+		c.file = nil
+	}
+}
+
+// output is what a command printed as a tool result shows it: stdout followed by
+// stderr, with the whitespace around them trimmed. It's read from the captures, so it
+// takes no more memory than they do.
+type output struct {
+	stdout, stderr *capture
+	content        *io.SectionReader
+}
+
+// newOutput combines the captures of a command's stdout and stderr
+func newOutput(stdout, stderr *capture) *output {
+	all := concatReaderAt{stdout.section(), io.NewSectionReader(bytes.NewReader([]byte("\n")), 0, 1), stderr.section()}
+	start, end := trimmedBounds(all, all.size())
+	return &output{
+		stdout:  stdout,
+		stderr:  stderr,
+		content: io.NewSectionReader(all, start, end-start),
+	}
+}
+
+// Size returns the length of the output in bytes
+func (o *output) Size() int64 {
+	return o.content.Size()
+}
+
+// reader returns a reader for the output from the start
+func (o *output) reader() io.Reader {
+	return io.NewSectionReader(o.content, 0, o.content.Size())
+}
+
+// String reads the whole output into memory
+func (o *output) String() string {
+	data, err := io.ReadAll(o.reader())
+	if err != nil {
+		debug("Failed to read output: %s", err)
+	}
+	return string(data)
+}
+
+// dropped returns a note for each stream that lost output because it couldn't be
+// kept, or "" if none did
+func (o *output) dropped() string {
+	var note strings.Builder
+	for _, stream := range []struct {
+		name    string
+		capture *capture
+	}{{"stdout", o.stdout}, {"stderr", o.stderr}} {
+		if err := stream.capture.err; err != nil {
+			fmt.Fprintf(&note, "\n\n[Output truncated: %s past %d bytes was dropped because it couldn't be kept: %s]", stream.name, stream.capture.size, err)
+		}
+	}
+	return note.String()
+}
+
+// Close removes the temporary files holding the output
+func (o *output) Close() {
+	o.stdout.close()
+	o.stderr.close()
+}
+
+// concatReaderAt reads several sections as one
+type concatReaderAt []*io.SectionReader
+
+func (c concatReaderAt) size() int64 {
+	var size int64
+	for _, part := range c {
+		size += part.Size()
+	}
+	return size
+}
+
+// ReadAt reads len(p) bytes starting at off, across as many sections as it takes
+func (c concatReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	read := 0
+	for _, part := range c {
+		if len(p) == 0 {
+			break
+		}
+		if off >= part.Size() {
+			off -= part.Size()
+			continue
+		}
+
+		n, err := part.ReadAt(p[:min(int64(len(p)), part.Size()-off)], off)
+		read += n
+		p = p[n:]
+		off = 0
+		if err != nil && !errors.Is(err, io.EOF) {
+			return read, err
+		}
+	}
+	if len(p) > 0 {
+		return read, io.EOF
+	}
+	return read, nil
+}
+
+// trimmedBounds returns where the content of r starts and ends once the whitespace
+// around it is trimmed, reading only the whitespace and a chunk past it
+func trimmedBounds(r io.ReaderAt, size int64) (start, end int64) {
+	chunk := make([]byte, 4096)
+
+	for start < size {
+		n, _ := r.ReadAt(chunk[:min(int64(len(chunk)), size-start)], start)
+		if n == 0 {
+			return size, size
+		}
+		if rest := bytes.TrimLeftFunc(chunk[:n], unicode.IsSpace); len(rest) > 0 {
+			start += int64(n - len(rest))
+			break
+		}
+		start += int64(n)
+	}
+
+	end = size
+	for end > start {
+		want := min(int64(len(chunk)), end-start)
+		n, _ := r.ReadAt(chunk[:want], end-want)
+		if int64(n) < want {
+			return start, start
+		}
+		if rest := bytes.TrimRightFunc(chunk[:n], unicode.IsSpace); len(rest) > 0 {
+			end = end - want + int64(len(rest))
+			break
+		}
+		end -= want
+	}
+	return start, end
+}
+
+// line is a line of output, or as much of its start and end as a lineScanner keeps
+type line struct {
+	start []byte // Up to the first keep bytes
+	end   []byte // Up to the last keep bytes
+	size  int    // Length of the whole line
+}
+
+// add adds part of the line, keeping only its first and last keep bytes
+func (l *line) add(part []byte, keep int) {
+	if len(l.start) < keep {
+		l.start = append(l.start, part[:min(keep-len(l.start), len(part))]...)
+	}
+	l.end = append(l.end, part...)
+	if len(l.end) > keep {
+		l.end = l.end[len(l.end)-keep:]
+	}
+	l.size += len(part)
+}
+
+// lineScanner reads output a line at a time like strings.Split(output, "\n"), in
+// memory that doesn't grow with the length of a line
+type lineScanner struct {
+	reader *bufio.Reader
+	keep   int // How much of the start and end of each line to keep
+	line   line
+	done   bool
+	err    error
+}
+
+// newLineScanner creates a lineScanner that keeps up to keep bytes at each end of a line
+func newLineScanner(r io.Reader, keep int) *lineScanner {
+	return &lineScanner{reader: bufio.NewReaderSize(r, 64<<10), keep: keep}
+}
+
+// scan reads the next line, returning false once there are no more
+func (s *lineScanner) scan() bool {
+	if s.done {
+		return false
+	}
+
+	s.line = line{}
+	for {
+		part, err := s.reader.ReadSlice('\n')
+		if err == nil {
+			s.line.add(part[:len(part)-1], s.keep)
+			return true
+		}
+
+		s.line.add(part, s.keep)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		// The last line has no newline, and may be empty
+		s.done = true
+		if !errors.Is(err, io.EOF) {
+			s.err = err
+		}
+		return true
+	}
+}
@@ -0,0 +1,216 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturedOutput creates the output of a command that printed stdout and stderr
+func capturedOutput(stdout, stderr string) *output {
+	outCapture, errCapture := &capture{}, &capture{}
+	outCapture.Write([]byte(stdout))
+	errCapture.Write([]byte(stderr))
+	return newOutput(outCapture, errCapture)
+}
+
+// spillAfter makes captures spill to a temporary directory past n bytes for the test,
+// and returns the directory
+func spillAfter(t *testing.T, n int) string {
+	threshold := spillThreshold
+	t.Cleanup(func() { spillThreshold = threshold })
+	spillThreshold = n
+
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	return dir
+}
+
+// tempFiles lists the files in dir
+func tempFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestTool_Capture(t *testing.T) {
+	dir := spillAfter(t, 10)
+
+	t.Run("keeps small output in memory", func(t *testing.T) {
+		c := &capture{}
+		c.Write([]byte("12345"))
+		c.Write([]byte("67890"))
+		assert.Nil(t, c.file)
+		assert.Equal(t, "1234567890", capturedOutput("1234567890", "").String())
+		assert.Empty(t, tempFiles(t, dir))
+	})
+
+	t.Run("spills past the threshold and removes the file on close", func(t *testing.T) {
+		c := &capture{}
+		c.Write([]byte("12345"))
+		c.Write([]byte("67890"))
+		c.Write([]byte("abc"))
+		require.NotNil(t, c.file)
+		assert.Zero(t, c.memory.Cap())
+		assert.Len(t, tempFiles(t, dir), 1)
+
+		output := newOutput(c, &capture{})
+		assert.Equal(t, int64(13), output.Size())
+		assert.Equal(t, "1234567890abc", output.String())
+
+		output.Close()
+		assert.Empty(t, tempFiles(t, dir))
+	})
+
+	t.Run("keeps what's in memory and notes the rest when it can't spill", func(t *testing.T) {
+		t.Setenv("TMPDIR", filepath.Join(dir, "missing"))
+
+		c := &capture{}
+		c.Write([]byte("12345"))
+		c.Write([]byte("67890"))
+		c.Write([]byte("abc"))
+		assert.Nil(t, c.file)
+		assert.Error(t, c.err)
+
+		var store *OutputStore
+		result := store.limit(newOutput(c, &capture{}))
+		assert.True(t, strings.HasPrefix(result, "1234567890\n\n[Output truncated: stdout past 10 bytes was dropped because it couldn't be kept: "), result)
+	})
+}
+
+func TestTool_OutputTrimsLikeTrimSpace(t *testing.T) {
+	spillAfter(t, 1000)
+
+	tests := []struct {
+		name   string
+		stdout string
+		stderr string
+	}{
+		{name: "nothing"},
+		{name: "only whitespace", stdout: " \n\t", stderr: "\r\n "},
+		{name: "stdout", stdout: "\n  out  \n"},
+		{name: "stderr", stderr: "\n  err  \n"},
+		{name: "both", stdout: "out\n", stderr: "err\n"},
+		{name: "unicode spaces", stdout: "\u00a0out", stderr: "err\u2003"},
+		{name: "whitespace longer than a chunk", stdout: strings.Repeat(" ", 5000) + "out", stderr: "err" + strings.Repeat("\n", 5000)},
+		{name: "spilled", stdout: strings.Repeat("out\n", 500), stderr: strings.Repeat("err\n", 500)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := capturedOutput(tt.stdout, tt.stderr)
+			defer output.Close()
+
+			expected := strings.TrimSpace(tt.stdout + "\n" + tt.stderr)
+			assert.Equal(t, expected, output.String())
+			assert.Equal(t, int64(len(expected)), output.Size())
+		})
+	}
+}
+
+func TestTool_LineScanner(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "one line", input: "one"},
+		{name: "trailing newline", input: "one\ntwo\n"},
+		{name: "blank lines", input: "\n\none\n\n"},
+		{name: "lines longer than the buffer", input: strings.Repeat("a", 100<<10) + "\nshort\n" + strings.Repeat("z", 70<<10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const keep = 4
+			scanner := newLineScanner(strings.NewReader(tt.input), keep)
+
+			var lines []string
+			for scanner.scan() {
+				expected := strings.Split(tt.input, "\n")[len(lines)]
+				assert.Equal(t, len(expected), scanner.line.size)
+				assert.Equal(t, expected[:min(keep, len(expected))], string(scanner.line.start))
+				assert.Equal(t, expected[max(0, len(expected)-keep):], string(scanner.line.end))
+				lines = append(lines, expected)
+			}
+			require.NoError(t, scanner.err)
+			assert.Len(t, lines, strings.Count(tt.input, "\n")+1)
+		})
+	}
+}
+
+func TestTool_ExecuteSpillsToDisk(t *testing.T) {
+	dir := spillAfter(t, 1024)
+
+	t.Run("returns all the output and removes the files", func(t *testing.T) {
+		output, err := execute(context.Background(), Options{}, nil, "seq", "10000")
+		require.NoError(t, err)
+		assert.Equal(t, numberedOutput(10000), output)
+		assert.Empty(t, tempFiles(t, dir))
+	})
+
+	t.Run("cuts output over the default byte limit without a store", func(t *testing.T) {
+		output, err := execute(context.Background(), Options{}, nil, "seq", "200000")
+		require.NoError(t, err)
+		assert.Less(t, len(output), defaultMaxBytes+100)
+		assert.True(t, strings.HasPrefix(output, "1\n2\n"), output[:10])
+		assert.True(t, strings.HasSuffix(output, "199999\n200000\n\n[Output truncated from 200000 lines and 1288894 bytes.]"), output[len(output)-100:])
+		assert.Empty(t, tempFiles(t, dir))
+	})
+
+	t.Run("keeps the files of truncated output until the store drops it", func(t *testing.T) {
+		store := NewOutputStore(4, 0)
+		server := mcp.NewServer("test-server", "1.0.0", nil)
+		server.AddTools(
+			NewServerTool(&MockBlueprint{commandArgs: []string{"seq", "10000"}}, Options{Name: "count", Outputs: store}),
+			NewReadOutputTool(store),
+		)
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go server.Run(ctx, serverTransport)
+
+		session, err := mcp.NewClient("test-client", "1.0.0", nil).Connect(ctx, clientTransport)
+		require.NoError(t, err)
+		defer session.Close()
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "count", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		assert.Equal(t, "1\n2\n… 9996 lines omitted …\n9999\n10000\n\n"+
+			`[Output truncated from 10000 lines and 48893 bytes. Call read_output with id "out-1" to read all of it.]`,
+			result.Content[0].(*mcp.TextContent).Text)
+		assert.Len(t, tempFiles(t, dir), 1)
+
+		result, err = session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      ReadOutputToolName,
+			Arguments: map[string]any{"id": "out-1", "offset": 9997},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "9998\n9999\n10000\n\n[Lines 9998-10000 of 10000, the end of the output.]",
+			result.Content[0].(*mcp.TextContent).Text)
+
+		store.Close()
+		assert.Empty(t, tempFiles(t, dir))
+	})
+}
+
+// numberedOutput returns what seq n prints, trimmed
+func numberedOutput(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i + 1)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,365 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ReadOutputToolName is the name of the tool that pages through truncated output
+const ReadOutputToolName = "read_output"
+
+// maxStoredOutputs is how many truncated outputs are kept for read_output. The
+// oldest are dropped first.
+const maxStoredOutputs = 20
+
+// defaultPageLines is how many lines read_output returns when there is no line limit
+const defaultPageLines = 200
+
+// defaultMaxBytes is the byte limit when none is set, so a few very long lines, or a
+// command printing gigabytes, can't put all of its output in a result
+const defaultMaxBytes = 512 << 10
+
+// OutputStore cuts tool output down to its limits, keeping the head and the tail,
+// and keeps the full output for read_output to page through
+type OutputStore struct {
+	MaxLines int // Most lines of output in a tool result; zero means no limit
+	MaxBytes int // Most bytes of output in a tool result; zero means defaultMaxBytes
+
+	mu      sync.Mutex
+	outputs map[string]storedOutput
+	order   []string // IDs from oldest to newest
+	next    int
+}
+
+// storedOutput is a truncated output kept for read_output
+type storedOutput struct {
+	output *output
+	lines  int
+}
+
+// NewOutputStore creates an OutputStore with the given limits
+func NewOutputStore(maxLines, maxBytes int) *OutputStore {
+	return &OutputStore{
+		MaxLines: maxLines,
+		MaxBytes: maxBytes,
+		outputs:  make(map[string]storedOutput),
+	}
+}
+
+// maxBytes is the byte limit in effect
+func (s *OutputStore) maxBytes() int {
+	if s.MaxBytes > 0 {
+		return s.MaxBytes
+	}
+	return defaultMaxBytes
+}
+
+// limit returns output as is if it fits. Otherwise it keeps the full output and
+// returns its first and last lines around a marker for the ones left out, followed by
+// how to read the rest. A nil store has no limits of its own, but still cuts output
+// over defaultMaxBytes, without keeping it, so a result never has to hold all of a
+// command's output.
+//
+// limit takes over output: it's closed once the result is built, or when the store
+// drops it.
+func (s *OutputStore) limit(output *output) string {
+	store := s
+	if store == nil {
+		store = &OutputStore{}
+	}
+	// Dropped output is noted after everything else, so it's never cut
+	dropped := output.dropped()
+
+	if store.MaxLines <= 0 && output.Size() <= int64(store.maxBytes()) {
+		defer output.Close()
+		return output.String() + dropped
+	}
+	head, tail, lines, err := store.headAndTail(output)
+	if err != nil {
+		debug("Failed to read output: %s", err)
+	}
+	if (store.MaxLines <= 0 || lines <= store.MaxLines) && output.Size() <= int64(store.maxBytes()) {
+		defer output.Close()
+		return output.String() + dropped
+	}
+
+	var result strings.Builder
+	result.WriteString(strings.Join(head, "\n"))
+	if omitted := lines - len(head) - len(tail); omitted > 0 {
+		fmt.Fprintf(&result, "\n… %s omitted …", countLines(omitted))
+	}
+	if len(tail) > 0 {
+		result.WriteString("\n" + strings.Join(tail, "\n"))
+	}
+
+	if s == nil {
+		defer output.Close()
+		fmt.Fprintf(&result, "\n\n[Output truncated from %s and %d bytes.]", countLines(lines), output.Size())
+	} else {
+		id := s.save(output, lines)
+		fmt.Fprintf(&result, "\n\n[Output truncated from %s and %d bytes. Call %s with id %q to read all of it.]",
+			countLines(lines), output.Size(), ReadOutputToolName, id)
+	}
+	return result.String() + dropped
+}
+
+// countLines writes a number of lines, like "1 line" or "5 lines"
+func countLines(n int) string {
+	if n == 1 {
+		return "1 line"
+	}
+	return fmt.Sprintf("%d lines", n)
+}
+
+// headAndTail splits the limits between the first and last lines of output, and
+// counts its lines. A line too long for its half of the byte limit is cut, with …
+// where the cut was. It reads the output once, keeping no more than the limits in
+// memory.
+func (s *OutputStore) headAndTail(output *output) (head, tail []string, lines int, err error) {
+	headLines, tailLines := (s.MaxLines+1)/2, s.MaxLines/2
+	if s.MaxLines <= 0 {
+		headLines, tailLines = math.MaxInt, math.MaxInt
+	}
+	headBytes, tailBudget := s.maxBytes()/2, s.maxBytes()/2
+
+	var (
+		headDone  bool
+		tailBytes int  // Bytes in tail, counting a newline after each line
+		tailCut   bool // Whether tail is a single line that was cut
+	)
+	// Keeping a byte more than the budget is enough to tell where to cut
+	scanner := newLineScanner(output.reader(), s.maxBytes()/2+1)
+	for scanner.scan() {
+		line := scanner.line
+		lines++
+
+		if !headDone && len(head) < headLines {
+			if line.size <= headBytes {
+				head = append(head, string(line.start))
+				headBytes -= line.size + 1
+				continue
+			}
+			headDone = true
+			if len(head) == 0 {
+				head = append(head, cutAfter(string(line.start), headBytes)+"…")
+				continue
+			}
+		}
+		headDone = true
+
+		if tailLines == 0 {
+			continue
+		}
+		// The tail is the most lines from the end that fit, or the end of the last line
+		// if even that doesn't fit
+		if line.size > tailBudget {
+			tail, tailBytes, tailCut = []string{"…" + cutBefore(string(line.end), tailBudget)}, 0, true
+			continue
+		}
+		if tailCut {
+			tail, tailCut = nil, false
+		}
+		tail = append(tail, string(line.start))
+		tailBytes += line.size + 1
+		for len(tail) > tailLines || tailBytes > tailBudget+1 {
+			tailBytes -= len(tail[0]) + 1
+			tail = tail[1:]
+		}
+	}
+	return head, tail, lines, scanner.err
+}
+
+// cutAfter returns the first n bytes of line without splitting a character
+func cutAfter(line string, n int) string {
+	for n > 0 && !utf8.RuneStart(line[n]) {
+		n--
+	}
+	return line[:n]
+}
+
+// cutBefore returns the last n bytes of line without splitting a character
+func cutBefore(line string, n int) string {
+	start := len(line) - n
+	for start < len(line) && !utf8.RuneStart(line[start]) {
+		start++
+	}
+	return line[start:]
+}
+
+// save keeps output under a new ID, dropping the oldest output if there are too many
+func (s *OutputStore) save(output *output, lines int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	id := fmt.Sprintf("out-%d", s.next)
+	s.outputs[id] = storedOutput{output: output, lines: lines}
+	s.order = append(s.order, id)
+	if len(s.order) > maxStoredOutputs {
+		s.outputs[s.order[0]].output.Close()
+		delete(s.outputs, s.order[0])
+		s.order = s.order[1:]
+	}
+	return id
+}
+
+// load returns the output kept under id
+func (s *OutputStore) load(id string) (storedOutput, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.outputs[id]
+	return stored, ok
+}
+
+// Close drops the outputs kept for read_output, removing their temporary files. A nil
+// store has nothing to close.
+func (s *OutputStore) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.outputs {
+		stored.output.Close()
+	}
+	s.outputs = make(map[string]storedOutput)
+	s.order = nil
+}
+
+// read returns up to limit lines of the output kept under id, starting at the line
+// offset, and says where the next page starts
+func (s *OutputStore) read(id string, offset, limit int) (string, error) {
+	if offset < 0 {
+		return "", fmt.Errorf("offset must be 0 or more, got %d", offset)
+	}
+	if limit < 1 {
+		return "", fmt.Errorf("limit must be 1 or more, got %d", limit)
+	}
+	stored, ok := s.load(id)
+	if !ok {
+		return "", fmt.Errorf("no output with id %q; only the last %d truncated outputs are kept", id, maxStoredOutputs)
+	}
+	if offset >= stored.lines {
+		return "", fmt.Errorf("offset %d is past the end of the output, which has %d lines", offset, stored.lines)
+	}
+
+	// Keep pages under the byte limit too, but always return at least part of a line
+	var page []string
+	size := -1
+	scanner := newLineScanner(stored.output.reader(), s.maxBytes()+1)
+	for i := 0; len(page) < limit && scanner.scan(); i++ {
+		if i < offset {
+			continue
+		}
+		line := scanner.line
+		if size+line.size+1 > s.maxBytes() {
+			if len(page) == 0 {
+				page = append(page, cutAfter(string(line.start), s.maxBytes())+"…")
+			}
+			break
+		}
+		page = append(page, string(line.start))
+		size += line.size + 1
+	}
+	if scanner.err != nil {
+		return "", fmt.Errorf("failed to read output %q: %w", id, scanner.err)
+	}
+
+	end := offset + len(page)
+	text := strings.Join(page, "\n")
+	if end < stored.lines {
+		return fmt.Sprintf("%s\n\n[Lines %d-%d of %d. Call %s with offset %d for more.]", text, offset+1, end, stored.lines, ReadOutputToolName, end), nil
+	}
+	return fmt.Sprintf("%s\n\n[Lines %d-%d of %d, the end of the output.]", text, offset+1, end, stored.lines), nil
+}
+
+// pageLines is how many lines read_output returns by default
+func (s *OutputStore) pageLines() int {
+	if s.MaxLines > 0 {
+		return s.MaxLines
+	}
+	return defaultPageLines
+}
+
+// NewReadOutputTool creates the read_output tool, which pages through the full output
+// of the tool results the store truncated
+func NewReadOutputTool(store *OutputStore) *mcp.ServerTool {
+	minOffset, minLimit := 0.0, 1.0
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"id": {
+				Type:        "string",
+				Description: "The id given in the truncated tool result",
+			},
+			"offset": {
+				Type:        "integer",
+				Description: "The line to start at, counting from 0",
+				Minimum:     &minOffset,
+				Default:     json.RawMessage("0"),
+			},
+			"limit": {
+				Type:        "integer",
+				Description: "The most lines to read",
+				Minimum:     &minLimit,
+				Default:     json.RawMessage(strconv.Itoa(store.pageLines())),
+			},
+		},
+		Required: []string{"id"},
+	}
+
+	handler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[map[string]any], error) {
+		id, ok := params.Arguments["id"].(string)
+		if !ok || id == "" {
+			return createToolResult("id must be the id string given in a truncated tool result", true), nil
+		}
+		offset, err := integerArgument(params.Arguments, "offset", 0)
+		if err != nil {
+			return createToolResult(err.Error(), true), nil
+		}
+		limit, err := integerArgument(params.Arguments, "limit", store.pageLines())
+		if err != nil {
+			return createToolResult(err.Error(), true), nil
+		}
+
+		page, err := store.read(id, offset, limit)
+		if err != nil {
+			return createToolResult(err.Error(), true), nil
+		}
+		return createToolResult(page, false), nil
+	}
+
+	serverTool := mcp.NewServerTool(
+		ReadOutputToolName,
+		"Read the full output of a tool result that was truncated, a page of lines at a time",
+		handler,
+		mcp.Input(mcp.Schema(schema)),
+	)
+	serverTool.Tool.Title = "Read truncated output"
+	serverTool.Tool.Annotations = readOnlyCommand.toolAnnotations()
+	return serverTool
+}
+
+// integerArgument returns the whole number argument with the given name, or
+// defaultValue if it's missing
+func integerArgument(arguments map[string]any, name string, defaultValue int) (int, error) {
+	value, ok := arguments[name]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) {
+		return 0, fmt.Errorf("%s must be a whole number, got %v", name, value)
+	}
+	return int(number), nil
+}
@@ -0,0 +1,241 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numberedLines returns n lines: "line 1" to "line n"
+func numberedLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestTool_OutputStoreLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxLines int
+		maxBytes int
+		output   string
+		expected string
+	}{
+		{
+			name:     "leaves output that fits",
+			maxLines: 5,
+			maxBytes: 100,
+			output:   numberedLines(5),
+			expected: numberedLines(5),
+		},
+		{
+			name:     "keeps the head and tail lines",
+			maxLines: 5,
+			output:   numberedLines(100),
+			expected: "line 1\nline 2\nline 3\n… 95 lines omitted …\nline 99\nline 100\n\n" +
+				`[Output truncated from 100 lines and 791 bytes. Call read_output with id "out-1" to read all of it.]`,
+		},
+		{
+			name:     "keeps the head and tail bytes",
+			maxBytes: 30,
+			output:   numberedLines(100),
+			expected: "line 1\nline 2\n… 97 lines omitted …\nline 100\n\n" +
+				`[Output truncated from 100 lines and 791 bytes. Call read_output with id "out-1" to read all of it.]`,
+		},
+		{
+			name:     "cuts a line that's too long",
+			maxBytes: 10,
+			output:   strings.Repeat("é", 20),
+			expected: "éé…\n\n" +
+				`[Output truncated from 1 line and 40 bytes. Call read_output with id "out-1" to read all of it.]`,
+		},
+		{
+			name:     "cuts long first and last lines",
+			maxBytes: 20,
+			output:   strings.Repeat("a", 30) + "\nmiddle\n" + strings.Repeat("z", 30),
+			expected: "aaaaaaaaaa…\n… 1 line omitted …\n…zzzzzzzzzz\n\n" +
+				`[Output truncated from 3 lines and 68 bytes. Call read_output with id "out-1" to read all of it.]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewOutputStore(tt.maxLines, tt.maxBytes)
+			assert.Equal(t, tt.expected, store.limit(capturedOutput(tt.output, "")))
+		})
+	}
+
+	t.Run("a nil store doesn't limit output", func(t *testing.T) {
+		var store *OutputStore
+		assert.Equal(t, numberedLines(100), store.limit(capturedOutput(numberedLines(100), "")))
+	})
+
+	t.Run("keeps only the latest outputs", func(t *testing.T) {
+		store := NewOutputStore(1, 0)
+		for i := 0; i < maxStoredOutputs+1; i++ {
+			store.limit(capturedOutput(numberedLines(2), ""))
+		}
+
+		_, err := store.read("out-1", 0, 10)
+		assert.EqualError(t, err, `no output with id "out-1"; only the last 20 truncated outputs are kept`)
+		_, err = store.read("out-2", 0, 10)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTool_OutputStoreRead(t *testing.T) {
+	store := NewOutputStore(3, 30)
+	store.limit(capturedOutput(numberedLines(10), ""))
+
+	tests := []struct {
+		name     string
+		offset   int
+		limit    int
+		expected string
+		errMsg   string
+	}{
+		{
+			name:     "reads a page",
+			offset:   0,
+			limit:    3,
+			expected: "line 1\nline 2\nline 3\n\n[Lines 1-3 of 10. Call read_output with offset 3 for more.]",
+		},
+		{
+			name:     "reads the last page",
+			offset:   8,
+			limit:    3,
+			expected: "line 9\nline 10\n\n[Lines 9-10 of 10, the end of the output.]",
+		},
+		{
+			name:     "keeps pages under the byte limit",
+			offset:   0,
+			limit:    10,
+			expected: "line 1\nline 2\nline 3\nline 4\n\n[Lines 1-4 of 10. Call read_output with offset 4 for more.]",
+		},
+		{
+			name:   "rejects offsets past the end",
+			offset: 10,
+			limit:  3,
+			errMsg: "offset 10 is past the end of the output, which has 10 lines",
+		},
+		{
+			name:   "rejects negative offsets",
+			offset: -5,
+			limit:  3,
+			errMsg: "offset must be 0 or more, got -5",
+		},
+		{
+			name:   "rejects a limit of zero",
+			offset: 2,
+			limit:  0,
+			errMsg: "limit must be 1 or more, got 0",
+		},
+		{
+			name:   "rejects negative limits",
+			offset: 0,
+			limit:  -1,
+			errMsg: "limit must be 1 or more, got -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := store.read("out-1", tt.offset, tt.limit)
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, page)
+		})
+	}
+}
+
+func TestTool_ReadOutputTool(t *testing.T) {
+	store := NewOutputStore(2, 0)
+	server := mcp.NewServer("test-server", "1.0.0", nil)
+	server.AddTools(
+		NewServerTool(&MockBlueprint{commandArgs: []string{"seq", "5"}}, Options{Name: "count", Outputs: store}),
+		NewReadOutputTool(store),
+	)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx, serverTransport)
+
+	session, err := mcp.NewClient("test-client", "1.0.0", nil).Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	defer session.Close()
+
+	call := func(name string, arguments map[string]any) (string, bool) {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: arguments})
+		require.NoError(t, err)
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	output, isError := call("count", map[string]any{})
+	assert.False(t, isError)
+	assert.Equal(t, "1\n… 3 lines omitted …\n5\n\n"+
+		`[Output truncated from 5 lines and 9 bytes. Call read_output with id "out-1" to read all of it.]`, output)
+
+	output, isError = call(ReadOutputToolName, map[string]any{"id": "out-1", "offset": 1})
+	assert.False(t, isError)
+	assert.Equal(t, "2\n3\n\n[Lines 2-3 of 5. Call read_output with offset 3 for more.]", output)
+
+	output, isError = call(ReadOutputToolName, map[string]any{"id": "out-9"})
+	assert.True(t, isError)
+	assert.Equal(t, `no output with id "out-9"; only the last 20 truncated outputs are kept`, output)
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		expected  string
+	}{
+		{
+			name:      "rejects a missing id",
+			arguments: map[string]any{},
+			expected:  "id must be the id string given in a truncated tool result",
+		},
+		{
+			name:      "rejects an id that isn't a string",
+			arguments: map[string]any{"id": 1},
+			expected:  "id must be the id string given in a truncated tool result",
+		},
+		{
+			name:      "rejects negative offsets",
+			arguments: map[string]any{"id": "out-1", "offset": -5, "limit": 3},
+			expected:  "offset must be 0 or more, got -5",
+		},
+		{
+			name:      "rejects a limit of zero",
+			arguments: map[string]any{"id": "out-1", "limit": 0},
+			expected:  "limit must be 1 or more, got 0",
+		},
+		{
+			name:      "rejects limits that aren't numbers",
+			arguments: map[string]any{"id": "out-1", "limit": "all"},
+			expected:  "limit must be a whole number, got all",
+		},
+		{
+			name:      "rejects offsets that aren't whole numbers",
+			arguments: map[string]any{"id": "out-1", "offset": 1.5},
+			expected:  "offset must be a whole number, got 1.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, isError := call(ReadOutputToolName, tt.arguments)
+			assert.True(t, isError)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
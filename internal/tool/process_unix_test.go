@@ -0,0 +1,67 @@
+//go:build !windows
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTool_ExecuteStopsProcessGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		cancelAfter time.Duration
+		err         error
+	}{
+		{name: "after a timeout", opts: Options{Timeout: 200 * time.Millisecond}, err: &TimeoutError{Timeout: 200 * time.Millisecond}},
+		{name: "when cancelled", cancelAfter: 200 * time.Millisecond, err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pidFile := filepath.Join(t.TempDir(), "child.pid")
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter > 0 {
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+
+			// The background sleep is a child the shell never waits for
+			_, err := execute(ctx, tt.opts, nil, "sh", "-c", "sleep 30 & echo $! > "+pidFile+"; wait")
+			require.Equal(t, tt.err, err)
+
+			data, err := os.ReadFile(pidFile)
+			require.NoError(t, err)
+			pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			require.NoError(t, err)
+
+			assert.Eventually(t, func() bool {
+				return !processRunning(pid)
+			}, 5*time.Second, 10*time.Millisecond, "child process %d is still running", pid)
+		})
+	}
+}
+
+// processRunning reports whether a process exists and isn't a zombie waiting for
+// init to reap it
+func processRunning(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true // No /proc outside Linux
+	}
+	_, state, _ := strings.Cut(string(stat), ") ")
+	return !strings.HasPrefix(state, "Z")
+}
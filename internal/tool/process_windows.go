@@ -0,0 +1,21 @@
+//go:build windows
+
+package tool
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, which has no process groups to signal
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the command, since Windows has no SIGTERM to ask it to
+// stop. Only the command itself is killed; processes it started keep running.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup kills the command, but not the processes it started
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
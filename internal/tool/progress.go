@@ -0,0 +1,184 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressTokenKey is the _meta key of the token a client sends to ask for progress
+const progressTokenKey = "progressToken"
+
+// progressInterval is the least time between progress notifications for a call. Lines
+// printed in between are sent together.
+var progressInterval = 250 * time.Millisecond
+
+// maxProgressLines is the most lines sent in one progress notification. When a command
+// prints more between two notifications only the latest are sent, since the result
+// has all of them anyway.
+const maxProgressLines = 100
+
+// maxProgressLineBytes is the most of a line sent as progress. The rest is left out, so
+// a command printing without newlines doesn't fill memory.
+const maxProgressLineBytes = 4096
+
+// progressReporter sends the lines a command prints as progress notifications, at
+// most one every progressInterval
+type progressReporter struct {
+	token  any
+	notify func(*mcp.ProgressNotificationParams)
+
+	mu      sync.Mutex
+	pending []string    // Lines not sent yet
+	skipped int         // Lines dropped from pending to keep under maxProgressLines
+	lines   int         // Lines printed so far, which is the progress
+	sent    time.Time   // When the last notification went out
+	timer   *time.Timer // Sends the pending lines once the interval is up
+	closed  bool
+}
+
+// newProgressReporter creates a progressReporter that sends notifications for the token
+func newProgressReporter(token any, notify func(*mcp.ProgressNotificationParams)) *progressReporter {
+	return &progressReporter{token: token, notify: notify}
+}
+
+// addLine queues a line, sending it right away if the last notification was long
+// enough ago
+func (r *progressReporter) addLine(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
+	r.lines++
+	r.pending = append(r.pending, line)
+	if len(r.pending) > maxProgressLines {
+		r.skipped += len(r.pending) - maxProgressLines
+		r.pending = r.pending[len(r.pending)-maxProgressLines:]
+	}
+
+	if r.timer != nil {
+		return
+	}
+	wait := progressInterval - time.Since(r.sent)
+	if wait <= 0 {
+		r.sendLocked()
+		return
+	}
+	r.timer = time.AfterFunc(wait, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.timer = nil
+		if !r.closed {
+			r.sendLocked()
+		}
+	})
+}
+
+// sendLocked sends the pending lines. The caller holds r.mu, which keeps the
+// notifications in order.
+func (r *progressReporter) sendLocked() {
+	if len(r.pending) == 0 {
+		return
+	}
+
+	message := strings.Join(r.pending, "\n")
+	if r.skipped > 0 {
+		message = fmt.Sprintf("(%d earlier lines not shown)\n%s", r.skipped, message)
+	}
+	r.notify(&mcp.ProgressNotificationParams{
+		ProgressToken: r.token,
+		Progress:      float64(r.lines),
+		Message:       message,
+	})
+
+	r.pending = nil
+	r.skipped = 0
+	r.sent = time.Now()
+}
+
+// close sends whatever is still pending. Nothing is sent after it returns, so the
+// notifications come before the tool result.
+func (r *progressReporter) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.sendLocked()
+	r.closed = true
+}
+
+// progressFor creates a progressReporter for a tool call if the client asked for
+// progress, or returns nil
+func progressFor(session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) *progressReporter {
+	token := params.GetMeta()[progressTokenKey]
+	if token == nil || session == nil {
+		return nil
+	}
+
+	return newProgressReporter(token, func(progress *mcp.ProgressNotificationParams) {
+		// The call's context may be cancelled, but the notification still belongs to it
+		if err := session.NotifyProgress(context.Background(), progress); err != nil {
+			debug("Failed to send progress: %s", err)
+		}
+	})
+}
+
+// lineWriter splits what one of a command's streams prints into lines for a
+// progressReporter
+type lineWriter struct {
+	reporter *progressReporter
+	partial  []byte // Printed after the last newline, up to maxProgressLineBytes
+	cut      bool   // Whether partial is missing the rest of the line
+}
+
+// Write reports every complete line in p
+func (w *lineWriter) Write(p []byte) (int, error) {
+	data := p
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.add(data[:i])
+		w.report()
+		data = data[i+1:]
+	}
+	w.add(data)
+	return len(p), nil
+}
+
+// add adds part of a line, cutting it at maxProgressLineBytes
+func (w *lineWriter) add(part []byte) {
+	if room := maxProgressLineBytes - len(w.partial); len(part) > room {
+		part = []byte(cutAfter(string(part), max(room, 0)))
+		w.cut = true
+	}
+	w.partial = append(w.partial, part...)
+}
+
+// report reports the line so far and starts the next one
+func (w *lineWriter) report() {
+	line := strings.TrimSuffix(string(w.partial), "\r")
+	if w.cut {
+		line += "…"
+	}
+	w.reporter.addLine(line)
+	w.partial = w.partial[:0]
+	w.cut = false
+}
+
+// flush reports a last line that didn't end with a newline
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 || w.cut {
+		w.report()
+	}
+}
@@ -0,0 +1,168 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordProgress creates a progressReporter that keeps what it sends
+func recordProgress() (*progressReporter, func() []*mcp.ProgressNotificationParams) {
+	var mu sync.Mutex
+	var sent []*mcp.ProgressNotificationParams
+	reporter := newProgressReporter("token", func(params *mcp.ProgressNotificationParams) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, params)
+	})
+	return reporter, func() []*mcp.ProgressNotificationParams {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*mcp.ProgressNotificationParams(nil), sent...)
+	}
+}
+
+func TestTool_ProgressReporter(t *testing.T) {
+	t.Run("sends the first line right away and batches the rest", func(t *testing.T) {
+		reporter, sent := recordProgress()
+
+		reporter.addLine("one")
+		reporter.addLine("two")
+		reporter.addLine("three")
+		require.Len(t, sent(), 1)
+		assert.Equal(t, &mcp.ProgressNotificationParams{ProgressToken: "token", Progress: 1, Message: "one"}, sent()[0])
+
+		require.Eventually(t, func() bool { return len(sent()) == 2 }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, &mcp.ProgressNotificationParams{ProgressToken: "token", Progress: 3, Message: "two\nthree"}, sent()[1])
+	})
+
+	t.Run("sends what's pending on close and nothing after", func(t *testing.T) {
+		reporter, sent := recordProgress()
+
+		reporter.addLine("one")
+		reporter.addLine("two")
+		reporter.close()
+		reporter.addLine("three")
+
+		time.Sleep(2 * progressInterval)
+		require.Len(t, sent(), 2)
+		assert.Equal(t, "two", sent()[1].Message)
+	})
+
+	t.Run("keeps the latest lines of a burst", func(t *testing.T) {
+		reporter, sent := recordProgress()
+
+		reporter.addLine("first")
+		for i := 0; i < maxProgressLines+5; i++ {
+			reporter.addLine("line")
+		}
+		reporter.addLine("last")
+		reporter.close()
+
+		require.Len(t, sent(), 2)
+		message := sent()[1].Message
+		assert.True(t, strings.HasPrefix(message, "(6 earlier lines not shown)\n"), message)
+		assert.True(t, strings.HasSuffix(message, "\nlast"), message)
+		assert.Len(t, strings.Split(message, "\n"), maxProgressLines+1)
+		assert.Equal(t, float64(maxProgressLines+7), sent()[1].Progress)
+	})
+}
+
+func TestTool_LineWriter(t *testing.T) {
+	reporter, sent := recordProgress()
+	writer := &lineWriter{reporter: reporter}
+
+	writer.Write([]byte("hel"))
+	writer.Write([]byte("lo\r\nwor"))
+	writer.Write([]byte("ld\n\nno newline"))
+	writer.flush()
+	reporter.close()
+
+	var lines []string
+	for _, params := range sent() {
+		lines = append(lines, strings.Split(params.Message, "\n")...)
+	}
+	assert.Equal(t, []string{"hello", "world", "", "no newline"}, lines)
+}
+
+func TestTool_StreamsProgress(t *testing.T) {
+	server := mcp.NewServer("test-server", "1.0.0", nil)
+	server.AddTools(NewServerTool(&MockBlueprint{
+		commandArgs: []string{"sh", "-c", "echo building; echo warning >&2; sleep 0.5; echo done"},
+	}, Options{Name: "build"}))
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx, serverTransport)
+
+	var mu sync.Mutex
+	var messages []string
+	client := mcp.NewClient("test-client", "1.0.0", &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, _ *mcp.ClientSession, params *mcp.ProgressNotificationParams) {
+			mu.Lock()
+			defer mu.Unlock()
+			messages = append(messages, params.Message)
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	defer session.Close()
+
+	t.Run("sends lines as progress when asked", func(t *testing.T) {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Meta:      mcp.Meta{"progressToken": "build"},
+			Name:      "build",
+			Arguments: map[string]any{},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "building\ndone\n\nwarning", result.Content[0].(*mcp.TextContent).Text)
+
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			got := strings.Join(messages, "\n")
+			return got == "building\nwarning\ndone" || got == "warning\nbuilding\ndone"
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("sends nothing without a progress token", func(t *testing.T) {
+		mu.Lock()
+		messages = nil
+		mu.Unlock()
+
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "build", Arguments: map[string]any{}})
+		require.NoError(t, err)
+
+		time.Sleep(2 * progressInterval)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Empty(t, messages)
+	})
+}
+
+func TestTool_LineWriterCutsLongLines(t *testing.T) {
+	reporter, sent := recordProgress()
+	writer := &lineWriter{reporter: reporter}
+
+	long := strings.Repeat("é", maxProgressLineBytes)
+	writer.Write([]byte("a" + long[:len(long)/2]))
+	writer.Write([]byte(long[len(long)/2:] + "\nshort\n" + long))
+	writer.flush()
+	reporter.close()
+
+	var lines []string
+	for _, params := range sent() {
+		lines = append(lines, strings.Split(params.Message, "\n")...)
+	}
+	require.Len(t, lines, 3)
+	assert.Equal(t, "a"+strings.Repeat("é", maxProgressLineBytes/2-1)+"…", lines[0])
+	assert.Equal(t, "short", lines[1])
+	assert.Equal(t, strings.Repeat("é", maxProgressLineBytes/2)+"…", lines[2])
+}
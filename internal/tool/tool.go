@@ -1,12 +1,16 @@
 package tool
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -20,6 +24,20 @@ type Blueprint interface {
 	GetInputSchema() interface{}
 }
 
+// Options customize how a blueprint is served and run as a tool
+type Options struct {
+	Name        string        // Tool name; defaults to the base command
+	Title       string        // Human readable name shown by clients
+	Description string        // Tool description; defaults to the command format
+	Dir         string        // Working directory for the command; defaults to the server's
+	Env         []string      // Extra environment variables as KEY=value
+	Timeout     time.Duration // Stops the command after this long; zero means no limit
+	Outputs     *OutputStore  // Limits the output in results and keeps it for read_output
+
+	Annotations      Annotations // Hints for clients about what the tool does
+	InferAnnotations bool        // Fill in undeclared hints from the known commands table
+}
+
 var debugMode bool
 
 // SetDebugMode enables or disables debug mode
@@ -39,25 +57,92 @@ func debug(format string, args ...interface{}) {
 	}
 }
 
-// Execute runs a command and returns trimmed combined stdout+stderr or an error
+// killGracePeriod is how long a timed out command has to stop after SIGTERM before
+// it gets SIGKILL
+var killGracePeriod = 5 * time.Second
+
+// TimeoutError reports a command that was stopped for running longer than its timeout
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.Timeout)
+}
+
+// Execute runs a command and returns trimmed combined stdout+stderr or an error.
+// Output over 512 KB is cut down to its first and last lines.
 func Execute(command string, args ...string) (string, error) {
+	return ExecuteContext(context.Background(), command, args...)
+}
+
+// ExecuteContext is like Execute, but stops the command along with everything it
+// started when the context is done
+func ExecuteContext(ctx context.Context, command string, args ...string) (string, error) {
+	return execute(ctx, Options{}, nil, command, args...)
+}
+
+// execute runs a command in the working directory and environment given by opts.
+// A command that outlives the timeout or the context is stopped along with everything
+// it started, and returns the output so far with a *TimeoutError or the context's error.
+// With a progress reporter, each line the command prints is reported as it's printed.
+func execute(ctx context.Context, opts Options, progress *progressReporter, command string, args ...string) (string, error) {
+	output, err := executeCaptured(ctx, opts, progress, command, args...)
+	return opts.Outputs.limit(output), err
+}
+
+// executeCaptured is like execute, but returns the output as it was captured, which
+// may be in temporary files. The caller closes it once it's done with it.
+func executeCaptured(ctx context.Context, opts Options, progress *progressReporter, command string, args ...string) (*output, error) {
 	debug("Executing command: %s %s", command, strings.Join(args, " "))
 
 	cmd := exec.Command(command, args...)
+	cmd.Dir = opts.Dir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+	setProcessGroup(cmd)
+	// Don't wait forever for output from processes that left the group
+	cmd.WaitDelay = killGracePeriod
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Output past what fits in memory goes to temporary files
+	stdout, stderr := &capture{}, &capture{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if progress != nil {
+		stdoutLines := &lineWriter{reporter: progress}
+		stderrLines := &lineWriter{reporter: progress}
+		defer stdoutLines.flush()
+		defer stderrLines.flush()
+		cmd.Stdout = io.MultiWriter(stdout, stdoutLines)
+		cmd.Stderr = io.MultiWriter(stderr, stderrLines)
+	}
 
-	err := cmd.Run()
+	err := run(ctx, cmd, opts.Timeout)
+	if errors.Is(err, exec.ErrWaitDelay) {
+		debug("Command exited but left processes holding its output open")
+		err = nil
+	}
 
 	// Always combine outputs for visibility
-	output := strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+	output := newOutput(stdout, stderr)
+
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		debug("Command %s", timeoutErr)
+		return output, err
+	}
+
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		debug("Command cancelled: %s", context.Cause(ctx))
+		debug("Output before it was stopped: %d bytes", output.Size())
+		return output, err
+	}
 
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			debug("Command completed with non-zero exit code: %d", exitErr.ExitCode())
-			debug("Final output length: %d chars", len(output))
+			debug("Final output length: %d bytes", output.Size())
 			return output, fmt.Errorf("command failed with exit code %d", exitErr.ExitCode())
 		}
 		debug("Spawn error: %s", err.Error())
@@ -65,13 +150,64 @@ func Execute(command string, args ...string) (string, error) {
 	}
 
 	debug("Command completed successfully with exit code 0")
-	debug("Final output length: %d chars", len(output))
+	debug("Final output length: %d bytes", output.Size())
 
 	return output, nil
 }
 
+// run starts the command and waits for it. After the timeout, if there is one, or
+// once the context is done, the command's process group gets SIGTERM, then SIGKILL
+// if it hasn't stopped after the grace period.
+func run(ctx context.Context, cmd *exec.Cmd, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var stopped error
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+		debug("Command timed out after %s, sending SIGTERM", timeout)
+		stopped = &TimeoutError{Timeout: timeout}
+	case <-ctx.Done():
+		debug("Command cancelled (%s), sending SIGTERM", context.Cause(ctx))
+		stopped = ctx.Err()
+	}
+	terminateProcessGroup(cmd)
+
+	select {
+	case <-done:
+	case <-time.After(killGracePeriod):
+		debug("Command still running after %s, sending SIGKILL", killGracePeriod)
+		killProcessGroup(cmd)
+		<-done
+	}
+	return stopped
+}
+
 // CreateToolFunction creates a tool handler for the given blueprint
 func CreateToolFunction(blueprint Blueprint) mcp.ToolHandlerFor[map[string]any, map[string]any] {
+	return createToolFunction(blueprint, Options{})
+}
+
+// createToolFunction creates a tool handler that runs the blueprint with the given options
+func createToolFunction(blueprint Blueprint, opts Options) mcp.ToolHandlerFor[map[string]any, map[string]any] {
 	return func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[map[string]any], error) {
 		debug("Tool called with args: %v", params.Arguments)
 
@@ -82,36 +218,106 @@ func CreateToolFunction(blueprint Blueprint) mcp.ToolHandlerFor[map[string]any,
 
 		debug("Built command: %s", strings.Join(fullCommand, " "))
 
-		output, err := Execute(fullCommand[0], fullCommand[1:]...)
+		progress := progressFor(session, params)
+		captured, err := executeCaptured(ctx, opts, progress, fullCommand[0], fullCommand[1:]...)
+		if progress != nil {
+			progress.close()
+		}
 		isError := err != nil
 
 		if isError {
 			debug("Execution error: %s", err)
 		}
 
+		output := opts.Outputs.limit(captured)
+
+		var timeoutErr *TimeoutError
+		if errors.As(err, &timeoutErr) {
+			output = timeoutOutput(timeoutErr, output)
+		}
+
 		return createToolResult(output, isError), nil
 	}
 }
 
-// GenerateToolName generates a tool name from a base command by replacing dashes with underscores
+// toolNamePattern matches the tool names MCP clients accept
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// invalidToolNameChars matches the characters replaced in generated tool names
+var invalidToolNameChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// scriptExtension matches file extensions like .sh or .py that are dropped from generated names
+var scriptExtension = regexp.MustCompile(`\.[A-Za-z]+$`)
+
+// GenerateToolName generates a valid tool name from a base command. Paths are reduced to
+// the file name, extensions like .sh are dropped and everything other than letters,
+// numbers and underscores becomes an underscore, so ./scripts/deploy-app.sh is deploy_app.
 func GenerateToolName(baseCommand string) string {
-	return strings.ReplaceAll(baseCommand, "-", "_")
+	name := filepath.Base(baseCommand)
+	if trimmed := scriptExtension.ReplaceAllString(name, ""); trimmed != "" {
+		name = trimmed
+	}
+
+	name = strings.Trim(invalidToolNameChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > 64 {
+		name = strings.TrimRight(name[:64], "_")
+	}
+	if name == "" {
+		return "tool"
+	}
+	return name
+}
+
+// ValidateToolName checks that a tool name will be accepted by MCP clients
+func ValidateToolName(name string) error {
+	if !toolNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid tool name %q: use up to 64 letters, numbers, underscores and dashes", name)
+	}
+	return nil
 }
 
 // CreateServerTool creates a complete MCP server tool from a blueprint
 func CreateServerTool(blueprint Blueprint) *mcp.ServerTool {
+	return NewServerTool(blueprint, Options{})
+}
+
+// NewServerTool creates a complete MCP server tool from a blueprint with the given options
+func NewServerTool(blueprint Blueprint, opts Options) *mcp.ServerTool {
+	name := opts.Name
+	if name == "" {
+		name = GenerateToolName(blueprint.GetBaseCommand())
+	}
+
+	description := opts.Description
+	if description == "" {
+		description = GetToolDescription(blueprint)
+	}
+
 	schema, ok := blueprint.GetInputSchema().(*jsonschema.Schema)
 	if !ok {
 		// This should never happen if the Blueprint interface is implemented correctly
 		panic("blueprint.GetInputSchema() must return *jsonschema.Schema")
 	}
 
-	return mcp.NewServerTool(
-		GenerateToolName(blueprint.GetBaseCommand()),
-		GetToolDescription(blueprint),
-		CreateToolFunction(blueprint),
+	serverTool := mcp.NewServerTool(
+		name,
+		description,
+		createToolFunction(blueprint, opts),
 		mcp.Input(mcp.Schema(schema)),
 	)
+	serverTool.Tool.Title = opts.Title
+	serverTool.Tool.Annotations = opts.Annotations.toolAnnotations()
+
+	return serverTool
+}
+
+// timeoutOutput tells the client a command timed out, followed by what it printed
+// before it was stopped
+func timeoutOutput(err *TimeoutError, output string) string {
+	if output == "" {
+		return fmt.Sprintf("Command %s with no output", err)
+	}
+	return fmt.Sprintf("Command %s. Output before it was stopped:\n%s", err, output)
 }
 
 func createToolResult(output string, isError bool) *mcp.CallToolResultFor[map[string]any] {
@@ -123,7 +329,16 @@ func createToolResult(output string, isError bool) *mcp.CallToolResultFor[map[st
 	}
 }
 
+// describer is implemented by blueprints that write their own default description,
+// like a dispatch tool listing its actions
+type describer interface {
+	GetDescription() string
+}
+
 // GetToolDescription generates the tool description from a blueprint
 func GetToolDescription(blueprint Blueprint) string {
+	if d, ok := blueprint.(describer); ok {
+		return d.GetDescription()
+	}
 	return "Run the shell command `" + blueprint.GetCommandFormat() + "`"
 }
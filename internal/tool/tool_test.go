@@ -3,8 +3,10 @@ package tool
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -72,6 +74,82 @@ func TestTool_Execute(t *testing.T) {
 	}
 }
 
+func TestTool_ExecuteTimeout(t *testing.T) {
+	t.Run("stops the command and keeps its output", func(t *testing.T) {
+		start := time.Now()
+		output, err := execute(context.Background(), Options{Timeout: 200 * time.Millisecond}, nil, "sh", "-c", "echo started; sleep 10; echo finished")
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.EqualError(t, err, "timed out after 200ms")
+		assert.Equal(t, "started", output)
+	})
+
+	t.Run("kills commands that ignore SIGTERM after the grace period", func(t *testing.T) {
+		defer func(grace time.Duration) { killGracePeriod = grace }(killGracePeriod)
+		killGracePeriod = 200 * time.Millisecond
+
+		start := time.Now()
+		_, err := execute(context.Background(), Options{Timeout: 100 * time.Millisecond}, nil, "sh", "-c", "trap '' TERM; sleep 10")
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		var timeoutErr *TimeoutError
+		assert.ErrorAs(t, err, &timeoutErr)
+	})
+
+	t.Run("doesn't stop commands that finish in time", func(t *testing.T) {
+		output, err := execute(context.Background(), Options{Timeout: 5 * time.Second}, nil, "echo", "quick")
+		assert.NoError(t, err)
+		assert.Equal(t, "quick", output)
+	})
+
+	t.Run("tells the client what happened", func(t *testing.T) {
+		handler := createToolFunction(&MockBlueprint{commandArgs: []string{"sh", "-c", "echo partial; sleep 10"}}, Options{Timeout: 100 * time.Millisecond})
+
+		result, err := handler(context.Background(), nil, &mcp.CallToolParamsFor[map[string]any]{})
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "Command timed out after 100ms. Output before it was stopped:\npartial", result.Content[0].(*mcp.TextContent).Text)
+	})
+}
+
+func TestTool_ExecuteCancel(t *testing.T) {
+	t.Run("stops the command and keeps its output", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		start := time.Now()
+		output, err := ExecuteContext(ctx, "sh", "-c", "echo started; sleep 10; echo finished")
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, "started", output)
+	})
+
+	t.Run("doesn't start commands once cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		marker := filepath.Join(t.TempDir(), "ran")
+		_, err := ExecuteContext(ctx, "touch", marker)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NoFileExists(t, marker)
+	})
+
+	t.Run("stops the command when the tool call is cancelled", func(t *testing.T) {
+		handler := createToolFunction(&MockBlueprint{commandArgs: []string{"sleep", "10"}}, Options{})
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		result, err := handler(ctx, nil, &mcp.CallToolParamsFor[map[string]any]{})
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
 func TestTool_DebugMode(t *testing.T) {
 	t.Run("debug mode is off by default", func(t *testing.T) {
 		assert.False(t, IsDebugMode())
@@ -188,6 +266,41 @@ func TestTool_GenerateToolName(t *testing.T) {
 			baseCommand: "simple_command",
 			expected:    "simple_command",
 		},
+		{
+			name:        "absolute path",
+			baseCommand: "/usr/local/bin/say",
+			expected:    "say",
+		},
+		{
+			name:        "relative script path with extension",
+			baseCommand: "./scripts/deploy-app.sh",
+			expected:    "deploy_app",
+		},
+		{
+			name:        "dotted version number",
+			baseCommand: "python3.11",
+			expected:    "python3_11",
+		},
+		{
+			name:        "dotfile",
+			baseCommand: "~/.bashrc",
+			expected:    "bashrc",
+		},
+		{
+			name:        "other characters",
+			baseCommand: "@scope/tool+extra",
+			expected:    "tool_extra",
+		},
+		{
+			name:        "nothing usable",
+			baseCommand: "./+++",
+			expected:    "tool",
+		},
+		{
+			name:        "long name",
+			baseCommand: strings.Repeat("a", 70),
+			expected:    strings.Repeat("a", 64),
+		},
 	}
 
 	for _, tt := range tests {
@@ -198,6 +311,34 @@ func TestTool_GenerateToolName(t *testing.T) {
 	}
 }
 
+func TestTool_ValidateToolName(t *testing.T) {
+	for _, name := range []string{"git", "git-status", "git_status", "Deploy2"} {
+		assert.NoError(t, ValidateToolName(name), name)
+	}
+
+	for _, name := range []string{"", "my tool", "deploy.sh", "./deploy", strings.Repeat("a", 65)} {
+		assert.ErrorContains(t, ValidateToolName(name), "invalid tool name", name)
+	}
+}
+
+func TestTool_NewServerTool(t *testing.T) {
+	blueprint := &MockBlueprint{commandArgs: []string{"echo", "hello"}}
+
+	t.Run("defaults to the base command and command format", func(t *testing.T) {
+		serverTool := NewServerTool(blueprint, Options{})
+		assert.Equal(t, "mock_tool", serverTool.Tool.Name)
+		assert.Equal(t, "", serverTool.Tool.Title)
+		assert.Equal(t, "Run the shell command `mock-tool`", serverTool.Tool.Description)
+	})
+
+	t.Run("uses the name, title and description options", func(t *testing.T) {
+		serverTool := NewServerTool(blueprint, Options{Name: "greet", Title: "Greeter", Description: "Say hello"})
+		assert.Equal(t, "greet", serverTool.Tool.Name)
+		assert.Equal(t, "Greeter", serverTool.Tool.Title)
+		assert.Equal(t, "Say hello", serverTool.Tool.Description)
+	})
+}
+
 // MockBlueprint is a test helper that implements the Blueprint interface
 type MockBlueprint struct {
 	commandArgs []string